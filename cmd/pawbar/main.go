@@ -15,6 +15,7 @@ import (
 	"git.sr.ht/~rockorager/vaxis"
 	"github.com/nekorg/katnip"
 	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/lookup/colors"
 	"github.com/nekorg/pawbar/internal/modules"
 	_ "github.com/nekorg/pawbar/internal/modules/all"
 	"github.com/nekorg/pawbar/internal/tui"
@@ -93,6 +94,8 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 	screenEvents := vx.Events()
 	userSignals := setupUserSignals()
 	resumeCh := watchResume(ctx)
+	paletteCh := watchPalette(vx)
+	paletteStale := false // the theme changed again while loading
 
 	var prevHoverMod modules.Module
 	var prevHoverCell modules.EventCell
@@ -103,6 +106,8 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 	utils.Logger.Printf("Panel Size (cells): %d, %d\n", w, h)
 	mouseShape := vaxis.MouseShapeDefault
 
+	modules.SetPanelSize(vx.Size())
+	refreshMonitor()
 	tui.Init(w, h, l, m, r, cfg.Bar)
 	tui.FullRender(win)
 	vx.Render()
@@ -114,6 +119,8 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 			switch ev := ev.(type) {
 			case vaxis.Resize:
 				pw, ph = ev.XPixel, ev.YPixel
+				modules.SetPanelSize(ev)
+				refreshMonitor()
 				win = vx.Window()
 				w, h = win.Size()
 				tui.Resize(w, h)
@@ -123,6 +130,14 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 			case vaxis.Redraw:
 				tui.FullRender(win)
 				vx.Render()
+			case vaxis.ColorThemeUpdate:
+				// loads read the same replies, so one at a time
+				if paletteCh != nil {
+					paletteStale = true
+				} else {
+					utils.Logger.Printf("color theme changed, reloading palette\n")
+					paletteCh = watchPalette(vx)
+				}
 			case vaxis.Key:
 				if ev.String() == "Ctrl+c" {
 					isRunning = false
@@ -197,6 +212,16 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 			tui.Resize(w, h)
 			tui.FullRender(win)
 			vx.Render()
+		case <-paletteCh:
+			utils.Logger.Printf("full render: palette loaded")
+			paletteCh = nil
+			if paletteStale {
+				paletteStale = false
+				utils.Logger.Printf("color theme changed while loading, reloading palette\n")
+				paletteCh = watchPalette(vx)
+			}
+			tui.FullRender(win)
+			vx.Render()
 		case <-resumeCh:
 			utils.Logger.Printf("full render: waking from suspend")
 			win = vx.Window()
@@ -209,6 +234,17 @@ func mainLoop(kitty *katnip.Kitty, rw io.ReadWriter) int {
 	return 0
 }
 
+// queries the palette off the main loop; the returned channel fires once it
+// is loaded so colors derived from it can be redrawn.
+func watchPalette(vx *vaxis.Vaxis) <-chan struct{} {
+	done := make(chan struct{}, 1)
+	go func() {
+		colors.LoadPalette(vx)
+		done <- struct{}{}
+	}()
+	return done
+}
+
 func updateMouseShape(
 	vx *vaxis.Vaxis,
 	ec modules.EventCell,
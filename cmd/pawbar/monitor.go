@@ -0,0 +1,35 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package main
+
+import (
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/output"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// finds the bar's output once, later calls only refresh it (the scale can
+// change at runtime, the output can't)
+func refreshMonitor() {
+	go func() {
+		var (
+			m   output.Monitor
+			err error
+		)
+		if name := modules.PanelMonitor().Name; name != "" {
+			m, err = output.Lookup(name)
+		} else {
+			m, err = output.Current()
+		}
+		if err != nil {
+			utils.Logger.Printf("monitor: %v, assuming 1x scale\n", err)
+			return
+		}
+		utils.Logger.Printf("monitor: %s %dx%d @%.2fx\n", m.Name, m.Width, m.Height, m.Scale.X)
+		modules.SetPanelMonitor(m)
+	}()
+}
@@ -53,7 +53,9 @@ export default defineConfig({
           { text: 'Custom', link: '#custom' }, 
           { text: 'Disk', link: '#disk' }, 
           { text: 'Idle Inhibitor', link: '#idleInhibitor' }, 
+          { text: 'Keyboard Layout', link: '#keyboard' }, 
           { text: 'Locale', link: '#locale' }, 
+          { text: 'Binding Mode', link: '#mode' }, 
           { text: 'Mpris', link: '#mpris' }, 
           { text: 'RAM', link: '#ram' }, 
           { text: 'Window Title', link: '#title' }, 
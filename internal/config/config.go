@@ -7,11 +7,8 @@
 package config
 
 import (
-	"os"
-
 	"github.com/nekorg/pawbar/internal/modules"
 	"github.com/nekorg/pawbar/internal/utils"
-	"gopkg.in/yaml.v3"
 )
 
 func InstantiateModules(cfg *BarConfig) (left, middle, right []modules.Module) {
@@ -23,13 +20,13 @@ func InstantiateModules(cfg *BarConfig) (left, middle, right []modules.Module) {
 }
 
 func Parse(path string) (*BarConfig, error) {
-	b, err := os.ReadFile(path)
+	root, err := load(path, make(map[string]bool))
 	if err != nil {
 		return nil, err
 	}
 
 	var cfg BarConfig
-	if err = yaml.Unmarshal(b, &cfg); err != nil {
+	if err = root.Decode(&cfg); err != nil {
 		return nil, err
 	}
 	cfg.Bar.FillDefaults()
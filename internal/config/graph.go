@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nekorg/pawbar/internal/lookup/colors"
+	"github.com/nekorg/pawbar/internal/modules"
+	"gopkg.in/yaml.v3"
+)
+
+// Graph configures the sparkline behind {{.Graph}} in modules that keep a
+// history of samples (see modules.History)
+type Graph struct {
+	Width      int              `yaml:"width"`
+	Style      GraphStyle       `yaml:"style"`
+	Max        float64          `yaml:"max"`
+	Thresholds []GraphThreshold `yaml:"thresholds"`
+}
+
+func (g *Graph) UnmarshalYAML(n *yaml.Node) error {
+	type plain Graph
+	if err := n.Decode((*plain)(g)); err != nil {
+		return err
+	}
+	// modules only keep so many samples, the rest would stay blank
+	if g.Samples() > modules.HistorySize {
+		return fmt.Errorf("graph: width %d needs %d samples, only %d are kept", g.Width, g.Samples(), modules.HistorySize)
+	}
+	return nil
+}
+
+type GraphStyle string
+
+const (
+	GraphBlock   GraphStyle = "block"
+	GraphBraille GraphStyle = "braille"
+)
+
+func (s *GraphStyle) UnmarshalYAML(n *yaml.Node) error {
+	var v string
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	switch GraphStyle(strings.ToLower(v)) {
+	case GraphBlock, "":
+		*s = GraphBlock
+	case GraphBraille:
+		*s = GraphBraille
+	default:
+		return fmt.Errorf(`graph style must be "block" or "braille", got %q`, v)
+	}
+	return nil
+}
+
+// colors every point at or above Percent (of Max), the highest matching
+// threshold wins. Fg is kept as text since the graph is emitted as markup
+type GraphThreshold struct {
+	Percent Percent `yaml:"percent"`
+	Fg      string  `yaml:"fg"`
+}
+
+func (t *GraphThreshold) UnmarshalYAML(n *yaml.Node) error {
+	type plain GraphThreshold
+	if err := n.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	if _, err := colors.ParseColor(t.Fg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Samples is how many samples the graph shows at its current width.
+func (g Graph) Samples() int {
+	if g.Style == GraphBraille {
+		return g.Width * 2
+	}
+	return g.Width
+}
+
+var (
+	blockLevels = []rune("▁▂▃▄▅▆▇█")
+
+	// braille dots filled bottom up, left and right column
+	brailleLeft  = []rune{0, 0x40, 0x44, 0x46, 0x47}
+	brailleRight = []rune{0, 0x80, 0xa0, 0xb0, 0xb8}
+)
+
+// Render draws values (oldest first) right aligned, padding the left when
+// there aren't enough samples yet.
+func (g Graph) Render(values []float64) string {
+	n := g.Samples()
+	if n <= 0 {
+		return ""
+	}
+	if len(values) > n {
+		values = values[len(values)-n:]
+	}
+
+	top := g.Max
+	if top <= 0 {
+		// scale to the largest sample shown, for values without a fixed top
+		for _, v := range values {
+			top = math.Max(top, v)
+		}
+		if top <= 0 {
+			top = 1
+		}
+	}
+	norm := make([]float64, n)
+	for i := range norm {
+		norm[i] = -1 // no sample
+	}
+	off := n - len(values)
+	for i, v := range values {
+		norm[off+i] = math.Min(math.Max(v/top, 0), 1)
+	}
+
+	var b strings.Builder
+	prev := ""
+	emit := func(r rune, level float64) {
+		fg := g.color(level)
+		if fg != prev {
+			if prev != "" {
+				b.WriteString("</fg>")
+			}
+			if fg != "" {
+				b.WriteString("<fg=" + fg + ">")
+			}
+			prev = fg
+		}
+		b.WriteRune(r)
+	}
+
+	if g.Style == GraphBraille {
+		for i := 0; i < n; i += 2 {
+			l, r := norm[i], norm[i+1]
+			emit(0x2800+braille(brailleLeft, l)+braille(brailleRight, r), math.Max(l, r))
+		}
+	} else {
+		for _, v := range norm {
+			if v < 0 {
+				emit(' ', v)
+				continue
+			}
+			emit(blockLevels[int(math.Round(v*float64(len(blockLevels)-1)))], v)
+		}
+	}
+
+	if prev != "" {
+		b.WriteString("</fg>")
+	}
+	return b.String()
+}
+
+func braille(dots []rune, v float64) rune {
+	if v < 0 {
+		return 0
+	}
+	// keep at least one dot so an idle graph still shows a baseline
+	return dots[max(1, int(math.Round(v*4)))]
+}
+
+func (g Graph) color(v float64) string {
+	fg := ""
+	best := -1
+	for _, t := range g.Thresholds {
+		p := t.Percent.Go()
+		if v >= 0 && v*100 >= float64(p) && p > best {
+			fg, best = t.Fg, p
+		}
+	}
+	return fg
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// top level keys handled here, they never reach BarConfig
+const (
+	keyInclude  = "include"
+	keyHosts    = "hosts"
+	keyDesktops = "desktops"
+)
+
+// loads a config file into a single mapping node with its includes merged in
+// and its host/desktop overrides applied.
+//
+// included files are merged first (in order) and act as the base, then the
+// file's own contents are merged on top: `bar` keys override and the
+// left/middle/right lists are appended. override blocks come last and
+// replace whole lists instead, so a host can drop modules from the base.
+func load(p string, seen map[string]bool) (*yaml.Node, error) {
+	abs, err := filepath.Abs(expandHome(p))
+	if err != nil {
+		return nil, err
+	}
+	if seen[abs] {
+		return nil, fmt.Errorf("include cycle: %q is already being loaded", abs)
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", abs, err)
+	}
+
+	root := newMapping()
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a map", abs)
+	}
+
+	includes, err := takeIncludes(root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", abs, err)
+	}
+	hosts := take(root, keyHosts)
+	desktops := take(root, keyDesktops)
+
+	base := newMapping()
+	for _, inc := range includes {
+		inc = expandHome(inc)
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(abs), inc)
+		}
+		n, err := load(inc, seen)
+		if err != nil {
+			return nil, err
+		}
+		merge(base, n, true)
+	}
+	merge(base, root, true)
+
+	if err := applyOverrides(base, hosts, []string{hostname()}); err != nil {
+		return nil, fmt.Errorf("%s: hosts: %w", abs, err)
+	}
+	if err := applyOverrides(base, desktops, currentDesktops()); err != nil {
+		return nil, fmt.Errorf("%s: desktops: %w", abs, err)
+	}
+
+	return base, nil
+}
+
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func deref(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// removes key from mapping m and returns its value
+func take(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return deref(v)
+		}
+	}
+	return nil
+}
+
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sets key to val, key is the node from the source mapping so tags like
+// the !!merge of "<<" survive
+func set(m *yaml.Node, key *yaml.Node, val *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key.Value {
+			m.Content[i] = key
+			m.Content[i+1] = val
+			return
+		}
+	}
+	m.Content = append(m.Content, key, val)
+}
+
+// include accepts a single path or a list of paths
+func takeIncludes(m *yaml.Node) ([]string, error) {
+	n := take(m, keyInclude)
+	if n == nil {
+		return nil, nil
+	}
+
+	var out []string
+	switch n.Kind {
+	case yaml.ScalarNode:
+		out = []string{n.Value}
+	case yaml.SequenceNode:
+		if err := n.Decode(&out); err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("include: must be a path or a list of paths")
+	}
+	return out, nil
+}
+
+func merge(dst, src *yaml.Node, appendLists bool) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key := src.Content[i]
+		val := deref(src.Content[i+1])
+		cur := deref(lookup(dst, key.Value))
+
+		switch key.Value {
+		case "left", "middle", "right":
+			if appendLists && cur != nil && cur.Kind == yaml.SequenceNode && val.Kind == yaml.SequenceNode {
+				list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				list.Content = append(append(list.Content, cur.Content...), val.Content...)
+				set(dst, key, list)
+				continue
+			}
+
+		case "<<":
+			// a map takes one merge key, later maps win so they go first
+			if key.Tag == "!!merge" && cur != nil {
+				list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				list.Content = append(append(list.Content, mergeMaps(val)...), mergeMaps(cur)...)
+				set(dst, key, list)
+				continue
+			}
+
+		case "bar":
+			if cur != nil && cur.Kind == yaml.MappingNode && val.Kind == yaml.MappingNode {
+				bar := newMapping()
+				merge(bar, cur, appendLists)
+				merge(bar, val, appendLists)
+				set(dst, key, bar)
+				continue
+			}
+		}
+
+		set(dst, key, val)
+	}
+}
+
+// the maps a merge key pulls in, it takes one or a list of them
+func mergeMaps(n *yaml.Node) []*yaml.Node {
+	if n.Kind != yaml.SequenceNode {
+		return []*yaml.Node{n}
+	}
+	out := make([]*yaml.Node, 0, len(n.Content))
+	for _, c := range n.Content {
+		out = append(out, deref(c))
+	}
+	return out
+}
+
+// override blocks are keyed by a glob matched against each of names,
+// every matching block is applied in file order
+func applyOverrides(base, blocks *yaml.Node, names []string) error {
+	if blocks == nil {
+		return nil
+	}
+	if blocks.Kind != yaml.MappingNode {
+		return fmt.Errorf("must be a map of name to overrides")
+	}
+
+	for i := 0; i+1 < len(blocks.Content); i += 2 {
+		pattern := blocks.Content[i].Value
+		block := deref(blocks.Content[i+1])
+		if block.Kind != yaml.MappingNode {
+			return fmt.Errorf("%q: overrides must be a map", pattern)
+		}
+
+		matched := false
+		for _, name := range names {
+			ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+			if err != nil {
+				return fmt.Errorf("%q: %w", pattern, err)
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+
+		if matched {
+			merge(base, block, false)
+		}
+	}
+	return nil
+}
+
+func hostname() string {
+	if h := os.Getenv("HOSTNAME"); h != "" {
+		return h
+	}
+	h, _ := os.Hostname()
+	return h
+}
+
+// XDG_CURRENT_DESKTOP is a colon separated list, like "sway:wlroots"
+func currentDesktops() []string {
+	return strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		return filepath.Join(os.Getenv("HOME"), p[1:])
+	}
+	return p
+}
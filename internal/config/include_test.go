@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func loadBar(t *testing.T, p string) BarSettings {
+	t.Helper()
+	root, err := load(p, make(map[string]bool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg BarConfig
+	if err := root.Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Bar
+}
+
+func TestIncludeMergeKey(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "base.yaml", `
+common: &common
+  ellipsis: "~"
+  enable_ellipsis: false
+bar:
+  <<: *common
+  truncate_priority: [left, middle, right]
+`)
+	main := write(t, dir, "main.yaml", `
+include: base.yaml
+bar:
+  hide_empty: true
+`)
+
+	bar := loadBar(t, main)
+	if bar.Ellipsis != "~" {
+		t.Errorf("ellipsis = %q, want the merged ~", bar.Ellipsis)
+	}
+	if bar.EnableEllipsis == nil || *bar.EnableEllipsis {
+		t.Errorf("enable_ellipsis = %v, want the merged false", bar.EnableEllipsis)
+	}
+	if bar.HideEmpty == nil || !*bar.HideEmpty {
+		t.Errorf("hide_empty = %v, want true", bar.HideEmpty)
+	}
+	if !slices.Equal(bar.TruncatePriority, []string{"left", "middle", "right"}) {
+		t.Errorf("truncate_priority = %v", bar.TruncatePriority)
+	}
+}
+
+func TestIncludeMergeKeyBothSides(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "base.yaml", `
+common: &common
+  ellipsis: "~"
+  enable_ellipsis: false
+bar:
+  <<: *common
+`)
+	main := write(t, dir, "main.yaml", `
+include: base.yaml
+mine: &mine
+  ellipsis: ">"
+bar:
+  <<: *mine
+`)
+
+	bar := loadBar(t, main)
+	if bar.Ellipsis != ">" {
+		t.Errorf("ellipsis = %q, want > from the including file", bar.Ellipsis)
+	}
+	if bar.EnableEllipsis == nil || *bar.EnableEllipsis {
+		t.Errorf("enable_ellipsis = %v, want false from the included file", bar.EnableEllipsis)
+	}
+}
@@ -8,7 +8,20 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"text/template"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/itchyny/timefmt-go"
+	"github.com/nekorg/pawbar/internal/lookup/icons"
+	"github.com/nekorg/pawbar/internal/lookup/units"
+	"github.com/nekorg/pawbar/internal/modules"
 )
 
 func Funcs() template.FuncMap {
@@ -21,9 +34,216 @@ func Funcs() template.FuncMap {
 				return fmt.Sprintf("%v", v)
 			}
 		},
+
+		"pad":   pad,
+		"lpad":  lpad,
+		"trunc": trunc,
+
+		"duration": duration,
+		"size":     func(v any) string { return size(v, units.IEC) },
+		"sizesi":   func(v any) string { return size(v, units.SI) },
+
+		"icon":  icon,
+		"bar":   bar,
+		"gauge": gauge,
+
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"default": dflt,
+		"replace": replace,
+		"escape":  modules.Escape,
+
+		"date": date,
 	}
 }
 
 func NewTemplate(src string) (*template.Template, error) {
 	return template.New("format").Funcs(Funcs()).Parse(src)
 }
+
+// pads s with spaces on the right up to n cells
+func pad(n int, v any) string {
+	s := fmt.Sprint(v)
+	if w := width(s); w < n {
+		s += strings.Repeat(" ", n-w)
+	}
+	return s
+}
+
+// pads s with spaces on the left up to n cells
+func lpad(n int, v any) string {
+	s := fmt.Sprint(v)
+	if w := width(s); w < n {
+		s = strings.Repeat(" ", n-w) + s
+	}
+	return s
+}
+
+// cuts s down to n cells, wide graphemes are never split
+func trunc(n int, v any) string {
+	s := fmt.Sprint(v)
+	var b strings.Builder
+	w := 0
+	for _, ch := range vaxis.Characters(s) {
+		if w+ch.Width > n {
+			break
+		}
+		w += ch.Width
+		b.WriteString(ch.Grapheme)
+	}
+	return b.String()
+}
+
+func width(s string) int {
+	w := 0
+	for _, ch := range vaxis.Characters(s) {
+		w += ch.Width
+	}
+	return w
+}
+
+// formats a time.Duration or a number of seconds as "1h 5m", "3m 20s" or
+// "45s", only the two largest units are kept
+func duration(v any) string {
+	var d time.Duration
+	switch x := v.(type) {
+	case time.Duration:
+		d = x
+	default:
+		d = time.Duration(toFloat(v) * float64(time.Second))
+	}
+
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = -d
+	}
+
+	days := int(d / (24 * time.Hour))
+	h := int(d/time.Hour) % 24
+	m := int(d/time.Minute) % 60
+	s := int(d/time.Second) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, h)
+	case h > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	default:
+		return fmt.Sprintf("%ds", s)
+	}
+}
+
+// formats a byte count with the largest fitting unit, like "1.5 GiB"
+func size(v any, sys units.System) string {
+	b := uint64(math.Max(toFloat(v), 0))
+	u := units.Choose(b, sys)
+	if u.Div == units.Byte {
+		return fmt.Sprintf("%d %s", b, u.Name)
+	}
+	return fmt.Sprintf("%.1f %s", units.Format(b, u), u.Name)
+}
+
+// picks a glyph from icons linearly based on percent, like icons.Choose
+func icon(set string, percent any) string {
+	r := []rune(set)
+	if len(r) == 0 {
+		return ""
+	}
+	return string(icons.Choose(r, int(toFloat(percent))))
+}
+
+var eighths = []rune{' ', '▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
+
+// horizontal bar n cells wide, filled to percent with eighth blocks
+func bar(n int, percent any) string {
+	if n <= 0 {
+		return ""
+	}
+	p := math.Min(math.Max(toFloat(percent), 0), 100)
+	filled := int(math.Round(p / 100 * float64(n*8)))
+
+	out := make([]rune, n)
+	for i := range out {
+		out[i] = eighths[min(max(filled-i*8, 0), 8)]
+	}
+	return string(out)
+}
+
+var levels = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// single cell vertical gauge
+func gauge(percent any) string {
+	p := math.Min(math.Max(toFloat(percent), 0), 100)
+	return string(levels[int(math.Round(p/100*8))])
+}
+
+// returns def when v is empty (zero value, empty string/slice, nil)
+func dflt(def, v any) any {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+	return v
+}
+
+var regexCache sync.Map // string -> *regexp.Regexp
+
+// regex replace, repl can use $1 style references
+func replace(pattern, repl string, v any) (string, error) {
+	var re *regexp.Regexp
+	if c, ok := regexCache.Load(pattern); ok {
+		re = c.(*regexp.Regexp)
+	} else {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		regexCache.Store(pattern, re)
+	}
+	return re.ReplaceAllString(fmt.Sprint(v), repl), nil
+}
+
+// strftime formatting (same as clock), t can be a time.Time or unix seconds
+func date(layout string, t any) string {
+	var tm time.Time
+	switch x := t.(type) {
+	case time.Time:
+		tm = x
+	default:
+		tm = time.Unix(int64(toFloat(t)), 0)
+	}
+	return timefmt.Format(tm, layout)
+}
+
+func toFloat(v any) float64 {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+		return f
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
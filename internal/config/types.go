@@ -7,7 +7,10 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -17,6 +20,7 @@ import (
 	"github.com/nekorg/pawbar/internal/lookup/icons"
 	"github.com/nekorg/pawbar/internal/lookup/units"
 	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,6 +28,7 @@ type BarSettings struct {
 	TruncatePriority []string `yaml:"truncate_priority"`
 	EnableEllipsis   *bool    `yaml:"enable_ellipsis"`
 	Ellipsis         string   `yaml:"ellipsis"`
+	HideEmpty        *bool    `yaml:"hide_empty"`
 }
 
 func (b *BarSettings) UnmarshalYAML(n *yaml.Node) error {
@@ -32,6 +37,10 @@ func (b *BarSettings) UnmarshalYAML(n *yaml.Node) error {
 		return err
 	}
 
+	// can be left out, FillDefaults takes care of it
+	if len(b.TruncatePriority) == 0 {
+		return nil
+	}
 	if len(b.TruncatePriority) != 3 {
 		return fmt.Errorf("truncate_priority: exactly 3 anchors needed, %d provided", len(b.TruncatePriority))
 	}
@@ -57,6 +66,10 @@ func (b *BarSettings) FillDefaults() {
 		t := true
 		b.EnableEllipsis = &t
 	}
+	if b.HideEmpty == nil {
+		f := false
+		b.HideEmpty = &f
+	}
 
 	if b.Ellipsis == "" {
 		b.Ellipsis = modules.ECELLIPSIS.C.Grapheme
@@ -149,7 +162,7 @@ func (c *Color) UnmarshalYAML(n *yaml.Node) error {
 	return nil
 }
 
-func (c Color) Go() vaxis.Color { return vaxis.Color(c) }
+func (c Color) Go() vaxis.Color { return colors.Resolve(vaxis.Color(c)) }
 
 type Percent int
 
@@ -249,3 +262,194 @@ func (d *Direction) UnmarshalYAML(n *yaml.Node) error {
 }
 func (d Direction) IsUp() bool { return bool(d) }
 func (d Direction) Go() bool   { return bool(d) }
+
+// OutputMode says which outputs a module shows things from
+type OutputMode int
+
+const (
+	OutputAll     OutputMode = iota
+	OutputCurrent            // only the bar's own output
+	OutputGroup              // all of them, grouped by output
+)
+
+func (o *OutputMode) UnmarshalYAML(n *yaml.Node) error {
+	var raw string
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+	switch strings.ToLower(raw) {
+	case "", "all":
+		*o = OutputAll
+	case "current", "bar":
+		*o = OutputCurrent
+	case "group", "grouped":
+		*o = OutputGroup
+	default:
+		return fmt.Errorf("%q is not a valid output mode. valid options are [%q, %q, %q]", raw, "all", "current", "group")
+	}
+	return nil
+}
+
+type SortOrder int
+
+const (
+	SortID SortOrder = iota
+	SortName
+)
+
+func (s *SortOrder) UnmarshalYAML(n *yaml.Node) error {
+	var raw string
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+	switch strings.ToLower(raw) {
+	case "", "id":
+		*s = SortID
+	case "name":
+		*s = SortName
+	default:
+		return fmt.Errorf("%q is not a valid sort order. valid options are [%q, %q]", raw, "id", "name")
+	}
+	return nil
+}
+
+// Backend picks how a window manager module talks to the compositor,
+// "auto" goes by the environment
+type Backend string
+
+const (
+	BackendAuto Backend = "auto"
+	BackendHypr Backend = "hypr"
+	BackendI3   Backend = "i3"
+	BackendNiri Backend = "niri"
+	BackendExec Backend = "exec"
+)
+
+func (b *Backend) UnmarshalYAML(n *yaml.Node) error {
+	var raw string
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+	switch v := Backend(strings.ToLower(raw)); v {
+	case "":
+		*b = BackendAuto
+	case BackendAuto, BackendHypr, BackendI3, BackendNiri, BackendExec:
+		*b = v
+	case "hyprland":
+		*b = BackendHypr
+	case "sway":
+		*b = BackendI3
+	default:
+		return fmt.Errorf("%q is not a valid backend. valid options are [%q, %q, %q, %q, %q]", raw, BackendAuto, BackendHypr, BackendI3, BackendNiri, BackendExec)
+	}
+	return nil
+}
+
+// Resolve turns auto into the backend for the running compositor, or exec
+// when there is none and a command is configured. it's empty when nothing
+// fits.
+func (b Backend) Resolve(haveExec bool) Backend {
+	if b != BackendAuto && b != "" {
+		return b
+	}
+	switch {
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return BackendHypr
+	case os.Getenv("I3SOCK") != "" || os.Getenv("SWAYSOCK") != "":
+		return BackendI3
+	case os.Getenv("NIRI_SOCKET") != "":
+		return BackendNiri
+	case haveExec:
+		return BackendExec
+	}
+	return ""
+}
+
+// Names is a list of names, where numeric ranges like "1-10" are expanded
+type Names []string
+
+func (ns *Names) UnmarshalYAML(n *yaml.Node) error {
+	var raw []string
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+
+	*ns = nil
+	for _, r := range raw {
+		from, to, ok := strings.Cut(r, "-")
+		a, errA := strconv.Atoi(from)
+		b, errB := strconv.Atoi(to)
+		if !ok || errA != nil || errB != nil {
+			*ns = append(*ns, r)
+			continue
+		}
+		if b < a || b-a > 100 {
+			return fmt.Errorf("invalid range %q", r)
+		}
+		for i := a; i <= b; i++ {
+			*ns = append(*ns, strconv.Itoa(i))
+		}
+	}
+	return nil
+}
+
+// Condition is a template expression like `eq .Status "Playing"`, the braces
+// are optional. It holds when the result is anything but empty, "false" or "0".
+type Condition struct {
+	*template.Template
+}
+
+func (c *Condition) UnmarshalYAML(n *yaml.Node) error {
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "{{") {
+		s = "{{" + s + "}}"
+	}
+
+	tmpl, err := NewTemplate(s)
+	if err != nil {
+		return fmt.Errorf("invalid condition %q: %w", s, err)
+	}
+
+	c.Template = tmpl
+	return nil
+}
+
+func (c Condition) Eval(data any) bool {
+	if c.Template == nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	if err := c.Execute(&buf, data); err != nil {
+		utils.Logger.Printf("condition error: %v\n", err)
+		return false
+	}
+
+	switch strings.TrimSpace(buf.String()) {
+	case "", "false", "0", "<no value>":
+		return false
+	}
+	return true
+}
+
+// Visibility is embedded inline in module options, so every module
+// accepts hide_when/show_when evaluated against its format data.
+type Visibility struct {
+	HideWhen Condition `yaml:"hide_when"`
+	ShowWhen Condition `yaml:"show_when"`
+}
+
+func (v Visibility) Hidden(data any) bool {
+	if v.HideWhen.Template != nil && v.HideWhen.Eval(data) {
+		return true
+	}
+	if v.ShowWhen.Template != nil && !v.ShowWhen.Eval(data) {
+		return true
+	}
+	return false
+}
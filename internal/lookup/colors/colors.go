@@ -61,6 +61,10 @@ func ParseColor(s string) (vaxis.Color, error) {
 		return vaxis.RGBColor(uint8(r), uint8(g), uint8(b)), nil
 	}
 
+	if c, ok, err := parseDerived(s); ok {
+		return c, err
+	}
+
 	if c, ok := named[strings.ToLower(s)]; ok {
 		return c, nil
 	}
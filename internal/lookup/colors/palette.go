@@ -0,0 +1,254 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package colors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// vaxis uses bit 24 for indexed and bit 25 for rgb colors, so bit 26 is
+// free for us. A derived color carries an index into the derivations table
+// in its lower 24 bits and is evaluated against the live palette whenever
+// it is resolved, so dim(@color4, 0.5) follows the terminal theme.
+const derived vaxis.Color = 1 << 26
+
+type derivation struct {
+	op   string
+	args []vaxis.Color
+	t    float64
+}
+
+var (
+	mu          sync.RWMutex
+	palette     [256]vaxis.Color
+	queried     [256]bool
+	fg, bg      vaxis.Color
+	derivations []derivation
+
+	// replies to the queries aren't tagged, two loads at once would take
+	// each other's
+	loadMu sync.Mutex
+)
+
+// LoadPalette queries the terminal for its 256 color palette along with the
+// default foreground and background (OSC 4/10/11). Entries the terminal does
+// not report keep their xterm defaults. This blocks on terminal responses,
+// so it must not be called from the vaxis event goroutine.
+func LoadPalette(vx *vaxis.Vaxis) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	var (
+		p [256]vaxis.Color
+		q [256]bool
+	)
+	if vx.CanReportColor() {
+		for i := range p {
+			c := vx.QueryColor(vaxis.IndexColor(uint8(i)))
+			if c != 0 {
+				p[i] = c
+				q[i] = true
+			}
+		}
+	}
+	f := vx.QueryForeground()
+	b := vx.QueryBackground()
+
+	mu.Lock()
+	defer mu.Unlock()
+	palette = p
+	queried = q
+	fg, bg = f, b
+}
+
+// Foreground returns the terminal's default foreground as an rgb color,
+// white if it could not be queried.
+func Foreground() vaxis.Color {
+	mu.RLock()
+	defer mu.RUnlock()
+	if fg == 0 {
+		return vaxis.RGBColor(255, 255, 255)
+	}
+	return fg
+}
+
+// Background returns the terminal's default background as an rgb color,
+// black if it could not be queried.
+func Background() vaxis.Color {
+	mu.RLock()
+	defer mu.RUnlock()
+	if bg == 0 {
+		return vaxis.RGBColor(0, 0, 0)
+	}
+	return bg
+}
+
+// Resolve evaluates derived colors against the current palette. Every other
+// color is returned as is, so indexed colors are still drawn by the terminal.
+func Resolve(c vaxis.Color) vaxis.Color {
+	if c&derived == 0 {
+		return c
+	}
+
+	mu.RLock()
+	id := int(c &^ derived)
+	if id >= len(derivations) {
+		mu.RUnlock()
+		return 0
+	}
+	d := derivations[id]
+	mu.RUnlock()
+
+	switch d.op {
+	case "dim":
+		return Dim(d.args[0], d.t)
+	case "mix":
+		return Mix(d.args[0], d.args[1], d.t)
+	}
+	return 0
+}
+
+// RGB returns the actual components of c. Indexed colors are looked up in
+// the live palette and the default color maps to the terminal foreground.
+func RGB(c vaxis.Color) (r, g, b uint8) {
+	c = Resolve(c)
+	if c == 0 {
+		c = Foreground()
+	}
+
+	p := c.Params()
+	switch len(p) {
+	case 3:
+		return p[0], p[1], p[2]
+	case 1:
+		mu.RLock()
+		pc, ok := palette[p[0]], queried[p[0]]
+		mu.RUnlock()
+		if !ok {
+			pc = xterm(p[0])
+		}
+		p = pc.Params()
+		return p[0], p[1], p[2]
+	}
+	return 0, 0, 0
+}
+
+// ToRGB converts c into an rgb color using the live palette.
+func ToRGB(c vaxis.Color) vaxis.Color {
+	return vaxis.RGBColor(RGB(c))
+}
+
+// Mix linearly interpolates between a (t=0) and b (t=1).
+func Mix(a, b vaxis.Color, t float64) vaxis.Color {
+	ar, ag, ab := RGB(a)
+	br, bg, bb := RGB(b)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return vaxis.RGBColor(lerp(ar, br), lerp(ag, bg), lerp(ab, bb))
+}
+
+// Dim fades c towards the terminal background, f=1 keeps it unchanged.
+func Dim(c vaxis.Color, f float64) vaxis.Color {
+	return Mix(c, Background(), 1-f)
+}
+
+func derive(op string, args []vaxis.Color, t float64) vaxis.Color {
+	mu.Lock()
+	defer mu.Unlock()
+	derivations = append(derivations, derivation{op: op, args: args, t: t})
+	return derived | vaxis.Color(len(derivations)-1)
+}
+
+// parses dim(<color>, <factor>) and mix(<color>, <color>, <ratio>)
+func parseDerived(s string) (vaxis.Color, bool, error) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return 0, false, nil
+	}
+	op := strings.ToLower(strings.TrimSpace(s[:open]))
+	if op != "dim" && op != "mix" {
+		return 0, false, nil
+	}
+
+	args := splitArgs(s[open+1 : len(s)-1])
+	want := 2
+	if op == "mix" {
+		want = 3
+	}
+	if len(args) != want {
+		return 0, true, fmt.Errorf("%s() takes %d arguments, got %d in %q", op, want, len(args), s)
+	}
+
+	t, err := strconv.ParseFloat(strings.TrimSpace(args[want-1]), 64)
+	if err != nil || t < 0 || t > 1 {
+		return 0, true, fmt.Errorf("%s(): ratio must be between 0 and 1 in %q", op, s)
+	}
+
+	cols := make([]vaxis.Color, want-1)
+	for i := range cols {
+		c, err := ParseColor(args[i])
+		if err != nil {
+			return 0, true, err
+		}
+		cols[i] = c
+	}
+
+	return derive(op, cols, t), true, nil
+}
+
+// splits on top level commas, rgb(...) arguments contain commas of their own
+func splitArgs(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+// default xterm palette, used until (or unless) the terminal reports its own
+func xterm(i uint8) vaxis.Color {
+	base := [16][3]uint8{
+		{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+		{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+		{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+		{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	}
+
+	switch {
+	case i < 16:
+		return vaxis.RGBColor(base[i][0], base[i][1], base[i][2])
+	case i < 232:
+		n := i - 16
+		level := func(v uint8) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return vaxis.RGBColor(level(n/36), level((n/6)%6), level(n%6))
+	default:
+		g := 8 + (i-232)*10
+		return vaxis.RGBColor(g, g, g)
+	}
+}
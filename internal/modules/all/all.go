@@ -18,8 +18,11 @@ import (
 	_ "github.com/nekorg/pawbar/internal/modules/custom"
 	_ "github.com/nekorg/pawbar/internal/modules/disk"
 	_ "github.com/nekorg/pawbar/internal/modules/idleInhibitor"
+	_ "github.com/nekorg/pawbar/internal/modules/keyboard"
 	_ "github.com/nekorg/pawbar/internal/modules/locale"
+	_ "github.com/nekorg/pawbar/internal/modules/mode"
 	_ "github.com/nekorg/pawbar/internal/modules/mpris"
+	_ "github.com/nekorg/pawbar/internal/modules/network"
 	_ "github.com/nekorg/pawbar/internal/modules/ram"
 	_ "github.com/nekorg/pawbar/internal/modules/title"
 	_ "github.com/nekorg/pawbar/internal/modules/tray"
@@ -214,13 +214,17 @@ func (mod *Backlight) Render() []modules.EventCell {
 		Max:     maxVal,
 	}
 
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	_ = mod.opts.Format.Execute(&buf, data)
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 	for i, ch := range rch {
 		r[i] = modules.EventCell{
-			C:          vaxis.Cell{Character: ch, Style: style},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
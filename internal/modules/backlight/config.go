@@ -22,6 +22,8 @@ type Options struct {
 	Format  config.Format                     `yaml:"format"`
 	Icons   []rune                            `yaml:"icons"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
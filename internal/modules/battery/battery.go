@@ -92,6 +92,25 @@ func pickThreshold(p int, th []ThresholdOptions) *ThresholdOptions {
 	return nil
 }
 
+func stateName(s uint32) string {
+	switch s {
+	case StateCharging:
+		return "Charging"
+	case StateDischarging:
+		return "Discharging"
+	case StateEmpty:
+		return "Empty"
+	case StateFullyCharged:
+		return "FullyCharged"
+	case StatePendingCharge:
+		return "PendingCharge"
+	case StatePendingDischarge:
+		return "PendingDischarge"
+	default:
+		return "Unknown"
+	}
+}
+
 func (mod *Battery) Render() []modules.EventCell {
 	percent := int(mod.device.Percentage)
 	style := vaxis.Style{}
@@ -134,28 +153,35 @@ func (mod *Battery) Render() []modules.EventCell {
 		style.Background = mod.opts.Bg.Go()
 	}
 
-	var buf bytes.Buffer
-
-	err := mod.opts.Format.Execute(&buf, struct {
+	data := struct {
 		Icon    string
 		Percent int
 		Hours   int
 		Minutes int
+		State   string
 	}{
 		Icon:    string(icon),
 		Percent: percent,
 		Hours:   eta / 3600,
 		Minutes: (eta / 60) % 60,
-	})
+		State:   stateName(mod.device.State),
+	}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	err := mod.opts.Format.Execute(&buf, data)
 	if err != nil {
 		utils.Logger.Printf("battery: render error: %v", err)
 	}
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 
 	for i, ch := range rch {
-		r[i] = modules.EventCell{C: vaxis.Cell{Character: ch, Style: style}, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
 	}
 	return r
 }
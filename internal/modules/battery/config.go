@@ -51,6 +51,8 @@ type Options struct {
 	Charged     ChargedOptions                    `yaml:"charged"`
 	Thresholds  []ThresholdOptions                `yaml:"thresholds"`
 	OnClick     config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -200,8 +200,13 @@ func (mod *bluetoothModule) Render() []modules.EventCell {
 	}
 
 	data := struct {
-		Device string
-	}{}
+		Device    string
+		Connected bool
+		Powered   bool
+	}{
+		Connected: mod.connected,
+		Powered:   mod.powered,
+	}
 
 	var tpl config.Format
 
@@ -210,25 +215,26 @@ func (mod *bluetoothModule) Render() []modules.EventCell {
 		tpl = mod.opts.NoConnection.Format
 		style.Foreground = mod.opts.NoConnection.Fg.Go()
 	case mod.connected:
-		data.Device = mod.device
+		data.Device = modules.Escape(mod.device)
 		tpl = mod.opts.Format
 	default:
 		tpl = mod.opts.Connection.Format
 	}
 
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	if err := tpl.Execute(&buf, data); err != nil {
 		return nil
 	}
 
-	chars := vaxis.Characters(buf.String())
+	chars := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(chars))
 	for i, ch := range chars {
 		r[i] = modules.EventCell{
-			C: vaxis.Cell{
-				Character: ch,
-				Style:     style,
-			},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
@@ -36,6 +36,8 @@ type Options struct {
 	Connection   ConnectionOptions                 `yaml:"connection"`
 	NoConnection NoConnectionOptions               `yaml:"noconnection"`
 	OnClick      config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -86,14 +86,16 @@ func (mod *ClockModule) Render() []modules.EventCell {
 	s.Foreground = mod.opts.Fg.Go()
 	s.Background = mod.opts.Bg.Go()
 
-	rch := vaxis.Characters(timefmt.Format(time.Now(), mod.opts.Format))
+	now := time.Now()
+	if mod.opts.Hidden(struct{ Time time.Time }{now}) {
+		return nil
+	}
+
+	rch := modules.Styled(timefmt.Format(now, mod.opts.Format), s)
 	r := make([]modules.EventCell, len(rch))
 	for i, ch := range rch {
 		r[i] = modules.EventCell{
-			C: vaxis.Cell{
-				Character: ch,
-				Style:     s,
-			},
+			C:          ch,
 			Metadata:   "",
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
@@ -39,6 +39,8 @@ type Options struct {
 	Tick    config.Duration                   `yaml:"tick"`
 	Format  string                            `yaml:"format"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
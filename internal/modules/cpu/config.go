@@ -66,7 +66,10 @@ type Options struct {
 	Tick      config.Duration                   `yaml:"tick"`
 	Format    config.Format                     `yaml:"format"`
 	Threshold ThresholdOptions                  `yaml:"threshold"`
+	Graph     config.Graph                      `yaml:"graph"`
 	OnClick   config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 // these field names need to match exactly the
@@ -81,6 +84,7 @@ type MouseOptions struct {
 	Cursor *config.Cursor   `yaml:"cursor"`
 	Tick   *config.Duration `yaml:"tick"`
 	Format *config.Format   `yaml:"format"`
+	Graph  *config.Graph    `yaml:"graph"`
 }
 
 func defaultOptions() Options {
@@ -89,6 +93,7 @@ func defaultOptions() Options {
 	return Options{
 		Format: config.Format{Template: f},
 		Tick:   config.Duration(3 * time.Second),
+		Graph:  config.Graph{Width: 10, Style: config.GraphBlock, Max: 100},
 		Threshold: ThresholdOptions{
 			Percent: 90,
 			For:     config.Duration(7 * time.Second),
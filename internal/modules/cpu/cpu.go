@@ -26,6 +26,8 @@ type CpuModule struct {
 	highStart     time.Time
 	highTriggered bool
 
+	history *modules.History
+
 	currentTickerInterval time.Duration
 	ticker                *time.Ticker
 }
@@ -38,6 +40,8 @@ func (mod *CpuModule) Run() (<-chan bool, chan<- modules.Event, error) {
 	mod.receive = make(chan bool)
 	mod.send = make(chan modules.Event)
 	mod.initialOpts = mod.opts
+	mod.history = modules.NewHistory(modules.HistorySize)
+	mod.sample()
 
 	go func() {
 		mod.currentTickerInterval = mod.opts.Tick.Go()
@@ -46,6 +50,7 @@ func (mod *CpuModule) Run() (<-chan bool, chan<- modules.Event, error) {
 		for {
 			select {
 			case <-mod.ticker.C:
+				mod.sample()
 				mod.receive <- true
 			case e := <-mod.send:
 				switch ev := e.VaxisEvent.(type) {
@@ -85,12 +90,18 @@ func (mod *CpuModule) ensureTickInterval() {
 	}
 }
 
-func (mod *CpuModule) Render() []modules.EventCell {
+// sampled on the ticker, so extra renders (clicks, hovers) don't
+// squeeze the graph or shorten the measuring window
+func (mod *CpuModule) sample() {
 	percent, err := cpu.Percent(0, false)
 	if err != nil || len(percent) == 0 {
-		return nil
+		return
 	}
-	usage := int(percent[0])
+	mod.history.Push(percent[0])
+}
+
+func (mod *CpuModule) Render() []modules.EventCell {
+	usage := int(mod.history.Last())
 
 	threshold := mod.opts.Threshold.Percent.Go()
 	if usage > threshold {
@@ -114,14 +125,22 @@ func (mod *CpuModule) Render() []modules.EventCell {
 		style.Background = mod.opts.Bg.Go()
 	}
 
+	data := struct {
+		Percent int
+		Graph   string
+	}{usage, mod.opts.Graph.Render(mod.history.Values())}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
-	_ = mod.opts.Format.Execute(&buf, struct{ Percent int }{usage})
+	_ = mod.opts.Format.Execute(&buf, data)
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 
 	for i, ch := range rch {
-		r[i] = modules.EventCell{C: vaxis.Cell{Character: ch, Style: style}, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
 	}
 	return r
 }
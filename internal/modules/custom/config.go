@@ -22,6 +22,8 @@ type Options struct {
 	Tick    config.Duration                   `yaml:"tick"`
 	Format  config.Format                     `yaml:"format"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
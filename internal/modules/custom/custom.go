@@ -81,14 +81,18 @@ func (mod *CustomModule) Render() []modules.EventCell {
 		Background: mod.opts.Bg.Go(),
 	}
 
+	if mod.opts.Hidden(nil) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	_ = mod.opts.Format.Execute(&buf, nil)
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 
 	for i, ch := range rch {
-		r[i] = modules.EventCell{C: vaxis.Cell{Character: ch, Style: style}, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
 	}
 	return r
 }
@@ -40,6 +40,8 @@ type Options struct {
 	Thresholds []ThresholdOptions `yaml:"thresholds"`
 
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -126,8 +126,7 @@ func (mod *DiskModule) Render() []modules.EventCell {
 		style.Background = mod.opts.Bg.Go()
 	}
 
-	var buf bytes.Buffer
-	err = mod.opts.Format.Execute(&buf, struct {
+	data := struct {
 		Used, Free, Total        float64
 		UsedPercent, FreePercent int
 		Unit, Icon               string
@@ -135,16 +134,22 @@ func (mod *DiskModule) Render() []modules.EventCell {
 		usedAbs, freeAbs, totalAbs,
 		usedPercent, freePercent,
 		unit.Name, mod.opts.Icon.Go(),
-	})
+	}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	err = mod.opts.Format.Execute(&buf, data)
 	if err != nil {
 		utils.Logger.Printf("fixme: disk: template error: %v\n", err)
 	}
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	out := make([]modules.EventCell, len(rch))
 	for i, ch := range rch {
 		out[i] = modules.EventCell{
-			C:          vaxis.Cell{Character: ch, Style: style},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
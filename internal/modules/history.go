@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package modules
+
+import "sync"
+
+// HistorySize is how many samples modules keep for their graphs, enough for
+// a 128 cell braille graph.
+const HistorySize = 256
+
+// History is a fixed size ring buffer of samples. it is written from the
+// module's ticker goroutine and read in Render, so it does its own locking.
+type History struct {
+	mu      sync.Mutex
+	samples []float64
+	next    int
+	full    bool
+}
+
+func NewHistory(size int) *History {
+	return &History{samples: make([]float64, max(size, 1))}
+}
+
+func (h *History) Push(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples[h.next] = v
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Values returns the samples oldest first.
+func (h *History) Values() []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]float64(nil), h.samples[:h.next]...)
+	}
+	return append(append([]float64(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// Last returns the newest sample, 0 if there is none yet.
+func (h *History) Last() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full && h.next == 0 {
+		return 0
+	}
+	return h.samples[(h.next+len(h.samples)-1)%len(h.samples)]
+}
@@ -28,6 +28,8 @@ type Options struct {
 	Format  config.Format                     `yaml:"format"`
 	Inhibit inhibitOptions                    `yaml:"inhibit"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
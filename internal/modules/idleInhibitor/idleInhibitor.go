@@ -170,19 +170,20 @@ func (mod *IdleModule) Render() []modules.EventCell {
 		style.Background = mod.opts.Inhibit.Bg.Go()
 
 	}
+	if mod.opts.Hidden(struct{ Inhibited bool }{mod.format == FormatInhibit}) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	if err := tlp.Execute(&buf, nil); err != nil {
 		return nil
 	}
 
-	chars := vaxis.Characters(buf.String())
+	chars := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(chars))
 	for i, ch := range chars {
 		r[i] = modules.EventCell{
-			C: vaxis.Cell{
-				Character: ch,
-				Style:     style,
-			},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package modules
+
+import (
+	"image"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// Image is drawn with kitty graphics over Width cells of the bar. the
+// renderer uploads it once and keeps it on the terminal for as long as some
+// module returns it, so keep the same *Image around between renders instead
+// of making a new one every time.
+type Image struct {
+	Img   image.Image
+	Width int
+}
+
+func NewImage(img image.Image, width int) *Image {
+	return &Image{Img: img, Width: max(width, 1)}
+}
+
+// Cells returns the blank cells the image is drawn over. they behave like
+// any other cell for clicks, hover and truncation; if the image gets
+// truncated it isn't drawn and the cells stay blank.
+func (im *Image) Cells(mod Module, style vaxis.Style, shape vaxis.MouseShape, metadata string) []EventCell {
+	out := make([]EventCell, im.Width)
+	for i := range out {
+		out[i] = EventCell{
+			C: vaxis.Cell{
+				Character: vaxis.Character{Grapheme: " ", Width: 1},
+				Style:     style,
+			},
+			Metadata:   metadata,
+			Mod:        mod,
+			MouseShape: shape,
+			Image:      im,
+		}
+	}
+	return out
+}
+
+// Blank is like Cells but the image isn't drawn, it stays uploaded for when
+// it's shown again (like a blinking icon).
+func (im *Image) Blank(mod Module, style vaxis.Style, shape vaxis.MouseShape, metadata string) []EventCell {
+	out := im.Cells(mod, style, shape, metadata)
+	for i := range out {
+		out[i].Hidden = true
+	}
+	return out
+}
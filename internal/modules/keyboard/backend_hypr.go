@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package keyboard
+
+import (
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+type hyprBackend struct {
+	svc    *hypr.Service
+	ev     chan hypr.HyprEvent
+	device string
+	sig    chan struct{}
+
+	mu     sync.RWMutex
+	layout string
+	main   string // name of the main keyboard, followed when device is empty
+}
+
+func newHyprBackend(s *hypr.Service, device string) backend {
+	b := &hyprBackend{
+		svc:    s,
+		ev:     make(chan hypr.HyprEvent),
+		device: device,
+		sig:    make(chan struct{}, 1),
+	}
+
+	b.refresh()
+	b.svc.RegisterChannel("activelayout", b.ev)
+	go b.loop()
+	return b
+}
+
+func (b *hyprBackend) loop() {
+	for e := range b.ev {
+		ev, err := e.Parse()
+		if err != nil {
+			utils.Logger.Println("keyboard:", err)
+			continue
+		}
+
+		switch ev := ev.(type) {
+		case hypr.ActiveLayoutEvent:
+			b.mu.Lock()
+			// virtual keyboards and the like report their own layouts
+			if ev.Keyboard == b.followed() {
+				b.layout = ev.Layout
+			}
+			b.mu.Unlock()
+		case hypr.ResyncEvent:
+			b.refresh()
+		}
+		b.signal()
+	}
+}
+
+func (b *hyprBackend) followed() string {
+	if b.device != "" {
+		return b.device
+	}
+	return b.main
+}
+
+func (b *hyprBackend) refresh() {
+	keyboards, err := hypr.Keyboards()
+	if err != nil {
+		utils.Logger.Println("keyboard:", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, kb := range keyboards {
+		if kb.Main {
+			b.main = kb.Name
+		}
+	}
+	for _, kb := range keyboards {
+		if kb.Name == b.followed() {
+			b.layout = kb.ActiveKeymap
+		}
+	}
+}
+
+func (b *hyprBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *hyprBackend) Layout() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.layout
+}
+
+func (b *hyprBackend) Switch(to string) error {
+	device := b.device
+	if device == "" {
+		device = "all"
+	}
+	return hypr.SwitchXkbLayout(device, to)
+}
+
+func (b *hyprBackend) Events() <-chan struct{} { return b.sig }
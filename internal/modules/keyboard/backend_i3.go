@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package keyboard
+
+import (
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// sway only, i3 leaves layouts to x11
+type i3Backend struct {
+	svc    *i3.Service
+	ev     chan interface{}
+	device string
+	sig    chan struct{}
+
+	mu     sync.RWMutex
+	layout string
+}
+
+func newI3Backend(s *i3.Service, device string) backend {
+	b := &i3Backend{
+		svc:    s,
+		ev:     make(chan interface{}),
+		device: device,
+		sig:    make(chan struct{}, 1),
+	}
+
+	b.refresh()
+	b.svc.RegisterChannel(i3.EventInput, b.ev)
+	go b.loop()
+	return b
+}
+
+func (b *i3Backend) loop() {
+	for e := range b.ev {
+		switch evt := e.(type) {
+		case i3.InputEvent:
+			if b.follows(evt.Input) {
+				b.set(evt.Input.XkbActiveLayoutName)
+				b.signal()
+			}
+		case i3.ResyncEvent:
+			b.refresh()
+			b.signal()
+		case i3.ErrorEvent:
+			utils.Logger.Println("keyboard: i3:", evt.Err)
+		default:
+			utils.Logger.Println("DEBUG: keyboard: i3: Unknown event type:", e)
+		}
+	}
+}
+
+func (b *i3Backend) follows(in i3.Input) bool {
+	if in.Type != "keyboard" || in.XkbActiveLayoutName == "" {
+		return false
+	}
+	return b.device == "" || in.Identifier == b.device
+}
+
+func (b *i3Backend) refresh() {
+	inputs, err := i3.GetInputs()
+	if err != nil {
+		utils.Logger.Println("keyboard: i3:", err)
+		return
+	}
+	for _, in := range inputs {
+		if b.follows(in) {
+			b.set(in.XkbActiveLayoutName)
+			return
+		}
+	}
+}
+
+func (b *i3Backend) set(layout string) {
+	b.mu.Lock()
+	b.layout = layout
+	b.mu.Unlock()
+}
+
+func (b *i3Backend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *i3Backend) Layout() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.layout
+}
+
+func (b *i3Backend) Switch(to string) error {
+	device := "type:keyboard"
+	if b.device != "" {
+		device = i3.Quote(b.device)
+	}
+	return i3.RunCommand("input " + device + " xkb_switch_layout " + to)
+}
+
+func (b *i3Backend) Events() <-chan struct{} { return b.sig }
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package keyboard
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/modules"
+)
+
+func init() {
+	config.RegisterModule("keyboard", defaultOptions, func(o Options) (modules.Module, error) { return &Module{opts: o}, nil })
+}
+
+type Options struct {
+	Fg     config.Color  `yaml:"fg"`
+	Bg     config.Color  `yaml:"bg"`
+	Cursor config.Cursor `yaml:"cursor"`
+	Format config.Format `yaml:"format"`
+
+	// keyboard to follow and switch, by name on hyprland and identifier on
+	// sway. the main keyboard (hyprland) or all of them (sway) when empty
+	Device string `yaml:"device"`
+	// layout name to short name, like "English (US)": "us"
+	Short map[string]string `yaml:"short"`
+
+	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
+}
+
+type MouseOptions struct {
+	Fg     *config.Color  `yaml:"fg"`
+	Bg     *config.Color  `yaml:"bg"`
+	Cursor *config.Cursor `yaml:"cursor"`
+	Format *config.Format `yaml:"format"`
+}
+
+func defaultOptions() Options {
+	f, _ := config.NewTemplate("{{.Short}}")
+	return Options{
+		Cursor: config.Cursor(vaxis.MouseShapeClickable),
+		Format: config.Format{Template: f},
+		OnClick: config.MouseActions[MouseOptions]{
+			Actions: map[string]*config.MouseAction[MouseOptions]{},
+		},
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package keyboard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+type backend interface {
+	// full xkb name of the active layout, like "English (US)"
+	Layout() string
+	// to is "next" or "prev"
+	Switch(to string) error
+	Events() <-chan struct{}
+}
+
+type Module struct {
+	b       backend
+	receive chan bool
+	send    chan modules.Event
+
+	opts        Options
+	initialOpts Options
+}
+
+func New() modules.Module { return &Module{} }
+
+func (mod *Module) Name() string                                  { return "keyboard" }
+func (mod *Module) Dependencies() []string                        { return nil }
+func (mod *Module) Channels() (<-chan bool, chan<- modules.Event) { return mod.receive, mod.send }
+
+func (mod *Module) Run() (<-chan bool, chan<- modules.Event, error) {
+	err := mod.selectBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mod.receive = make(chan bool)
+	mod.send = make(chan modules.Event)
+	mod.initialOpts = mod.opts
+
+	go func() {
+		render := mod.b.Events()
+		for {
+			select {
+			case e := <-mod.send:
+				switch ev := e.VaxisEvent.(type) {
+				case vaxis.Mouse:
+					if ev.EventType != vaxis.EventPress {
+						break
+					}
+					if mod.dispatch(ev) {
+						mod.receive <- true
+					}
+				case modules.FocusIn:
+					if mod.opts.OnClick.HoverIn(&mod.opts) {
+						mod.receive <- true
+					}
+				case modules.FocusOut:
+					if mod.opts.OnClick.HoverOut(&mod.opts) {
+						mod.receive <- true
+					}
+				}
+
+			case <-render:
+				mod.receive <- true
+			}
+		}
+	}()
+
+	return mod.receive, mod.send, nil
+}
+
+// onmouse actions replace the default of their button, which is left click
+// and wheel down for the next layout and wheel up for the previous one
+func (mod *Module) dispatch(ev vaxis.Mouse) bool {
+	btn := config.ButtonName(ev)
+	if _, ok := mod.opts.OnClick.Actions[btn]; ok {
+		return mod.opts.OnClick.Dispatch(btn, &mod.initialOpts, &mod.opts)
+	}
+
+	to := ""
+	switch ev.Button {
+	case vaxis.MouseLeftButton, vaxis.MouseWheelDown:
+		to = "next"
+	case vaxis.MouseWheelUp:
+		to = "prev"
+	default:
+		return false
+	}
+
+	// the layout event triggers the render
+	go func() {
+		if err := mod.b.Switch(to); err != nil {
+			utils.Logger.Println("keyboard:", err)
+		}
+	}()
+	return false
+}
+
+func (mod *Module) selectBackend() error {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		svc, ok := hypr.Register()
+		if !ok {
+			return fmt.Errorf("Could not start hypr service.")
+		}
+		mod.b = newHyprBackend(svc, mod.opts.Device)
+	} else if os.Getenv("SWAYSOCK") != "" {
+		svc, ok := i3.Register()
+		if !ok {
+			return fmt.Errorf("Could not start i3 service.")
+		}
+		mod.b = newI3Backend(svc, mod.opts.Device)
+	} else {
+		return fmt.Errorf("Could not find a wm backend for current environment.")
+	}
+
+	return nil
+}
+
+// short name from the config, otherwise the first two letters of the name
+func (mod *Module) short(layout string) string {
+	if s, ok := mod.opts.Short[layout]; ok {
+		return s
+	}
+	r := []rune(strings.ToLower(layout))
+	return string(r[:min(2, len(r))])
+}
+
+func (mod *Module) Render() []modules.EventCell {
+	layout := mod.b.Layout()
+	if layout == "" {
+		return nil
+	}
+
+	data := struct {
+		Layout string
+		Short  string
+	}{
+		Layout: modules.Escape(layout),
+		Short:  modules.Escape(mod.short(layout)),
+	}
+
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	style := vaxis.Style{
+		Foreground: mod.opts.Fg.Go(),
+		Background: mod.opts.Bg.Go(),
+	}
+
+	var buf bytes.Buffer
+	_ = mod.opts.Format.Execute(&buf, data)
+
+	rch := modules.Styled(buf.String(), style)
+	r := make([]modules.EventCell, len(rch))
+	for i, ch := range rch {
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+	}
+	return r
+}
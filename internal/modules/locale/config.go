@@ -24,6 +24,8 @@ type Options struct {
 	Format  config.Format                     `yaml:"format"`
 	Tick    config.Duration                   `yaml:"tick"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -159,14 +159,18 @@ func (mod *LocaleModule) Render() []modules.EventCell {
 		Locale: locale,
 	}
 
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	_ = mod.opts.Format.Execute(&buf, data)
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 
 	for i, ch := range rch {
-		r[i] = modules.EventCell{C: vaxis.Cell{Character: ch, Style: style}, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
 	}
 	return r
 }
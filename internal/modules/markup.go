@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package modules
+
+import (
+	"strings"
+	"sync"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/lookup/colors"
+)
+
+// Styled turns formatted module output into cells, applying inline markup
+// on top of base:
+//
+//	<fg=@urgent>..</fg> <bg=#303030>..</bg>
+//	<b> <i> <u> <s> <dim> <blink> <reverse>
+//	<lt> for a literal '<'
+//
+// tags nest and a closing tag ends the innermost open tag of that name, </>
+// ends whichever was opened last. anything that isn't a valid tag (like
+// "<3" or an unknown color) is kept as text.
+func Styled(s string, base vaxis.Style) []vaxis.Cell {
+	if !strings.Contains(s, "<") {
+		return styledText(nil, s, base)
+	}
+
+	type open struct {
+		name  string
+		style vaxis.Style
+	}
+
+	var (
+		cells []vaxis.Cell
+		stack []open
+		cur   = base
+	)
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			break
+		}
+		gt := strings.IndexByte(s[lt:], '>')
+		if gt < 0 {
+			break
+		}
+		cells = styledText(cells, s[:lt], cur)
+		tag := s[lt+1 : lt+gt]
+		rest := s[lt+gt+1:]
+
+		if tag == "lt" {
+			cells = styledText(cells, "<", cur)
+			s = rest
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(tag, "/"); ok {
+			i := len(stack) - 1
+			for name != "" && i >= 0 && stack[i].name != name {
+				i--
+			}
+			if i >= 0 {
+				cur = stack[i].style
+				stack = stack[:i]
+				s = rest
+				continue
+			}
+			// already closed by an outer tag, drop it
+			if _, _, ok := applyTag(name, cur); ok || name == "fg" || name == "bg" {
+				s = rest
+				continue
+			}
+		} else if name, next, ok := applyTag(tag, cur); ok {
+			stack = append(stack, open{name: name, style: cur})
+			cur = next
+			s = rest
+			continue
+		}
+
+		// not markup, keep the '<' and carry on after it
+		cells = styledText(cells, "<", cur)
+		s = s[lt+1:]
+	}
+	return styledText(cells, s, cur)
+}
+
+// Escape makes s render as is, for text that doesn't come from the config
+// like window titles. every '<' becomes a single stand-in rune instead of a
+// tag, so trunc, pad and friends still count the text right.
+func Escape(s string) string {
+	return strings.ReplaceAll(s, "<", escapedLt)
+}
+
+// a unicode noncharacter, it never shows up in real text
+const escapedLt = "\uFDD0"
+
+func styledText(cells []vaxis.Cell, s string, style vaxis.Style) []vaxis.Cell {
+	s = strings.ReplaceAll(s, escapedLt, "<")
+	for _, ch := range vaxis.Characters(s) {
+		cells = append(cells, vaxis.Cell{Character: ch, Style: style})
+	}
+	return cells
+}
+
+func applyTag(tag string, s vaxis.Style) (string, vaxis.Style, bool) {
+	name, val, hasVal := strings.Cut(tag, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if hasVal {
+		c, ok := markupColor(val)
+		if !ok {
+			return "", s, false
+		}
+		switch name {
+		case "fg":
+			s.Foreground = c
+		case "bg":
+			s.Background = c
+		default:
+			return "", s, false
+		}
+		return name, s, true
+	}
+
+	switch name {
+	case "b":
+		s.Attribute |= vaxis.AttrBold
+	case "i":
+		s.Attribute |= vaxis.AttrItalic
+	case "u":
+		s.UnderlineStyle = vaxis.UnderlineSingle
+	case "s":
+		s.Attribute |= vaxis.AttrStrikethrough
+	case "dim":
+		s.Attribute |= vaxis.AttrDim
+	case "blink":
+		s.Attribute |= vaxis.AttrBlink
+	case "reverse":
+		s.Attribute |= vaxis.AttrReverse
+	default:
+		return "", s, false
+	}
+	return name, s, true
+}
+
+// parsing derived colors registers them, so parse every value only once
+var (
+	markupMu     sync.Mutex
+	markupColors = map[string]vaxis.Color{}
+	markupBad    = map[string]bool{}
+)
+
+func markupColor(v string) (vaxis.Color, bool) {
+	v = strings.TrimSpace(v)
+
+	markupMu.Lock()
+	c, ok := markupColors[v]
+	bad := markupBad[v]
+	if !ok && !bad {
+		var err error
+		c, err = colors.ParseColor(v)
+		if err != nil {
+			markupBad[v] = true
+			bad = true
+		} else {
+			markupColors[v] = c
+		}
+	}
+	markupMu.Unlock()
+
+	if bad {
+		return 0, false
+	}
+	return colors.Resolve(c), true
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package mode
+
+import (
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+type hyprBackend struct {
+	svc  *hypr.Service
+	ev   chan hypr.HyprEvent
+	mu   sync.RWMutex
+	mode string
+	sig  chan struct{}
+}
+
+func newHyprBackend(s *hypr.Service) backend {
+	b := &hyprBackend{
+		svc: s,
+		ev:  make(chan hypr.HyprEvent),
+		sig: make(chan struct{}, 1),
+	}
+
+	b.refresh()
+	b.svc.RegisterChannel("submap", b.ev)
+	go b.loop()
+	return b
+}
+
+func (b *hyprBackend) loop() {
+	for e := range b.ev {
+		ev, err := e.Parse()
+		if err != nil {
+			utils.Logger.Println("mode:", err)
+			continue
+		}
+
+		switch ev := ev.(type) {
+		case hypr.SubmapEvent:
+			b.set(ev.Name)
+		case hypr.ResyncEvent:
+			b.refresh()
+		}
+		b.signal()
+	}
+}
+
+func (b *hyprBackend) refresh() {
+	submap, err := hypr.Submap()
+	if err != nil {
+		utils.Logger.Println("mode:", err)
+		return
+	}
+	b.set(submap)
+}
+
+func (b *hyprBackend) set(mode string) {
+	b.mu.Lock()
+	b.mode = mode
+	b.mu.Unlock()
+}
+
+func (b *hyprBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *hyprBackend) Mode() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.mode
+}
+func (b *hyprBackend) Events() <-chan struct{} { return b.sig }
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package mode
+
+import (
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+type i3Backend struct {
+	svc  *i3.Service
+	ev   chan interface{}
+	mu   sync.RWMutex
+	mode string
+	sig  chan struct{}
+}
+
+func newI3Backend(s *i3.Service) backend {
+	b := &i3Backend{
+		svc: s,
+		ev:  make(chan interface{}),
+		sig: make(chan struct{}, 1),
+	}
+
+	b.refresh()
+	b.svc.RegisterChannel(i3.EventMode, b.ev)
+	go b.loop()
+	return b
+}
+
+func (b *i3Backend) loop() {
+	for e := range b.ev {
+		switch evt := e.(type) {
+		case i3.ModeEvent:
+			b.set(evt.Change)
+			b.signal()
+		case i3.ResyncEvent:
+			b.refresh()
+			b.signal()
+		case i3.ErrorEvent:
+			utils.Logger.Println("mode: i3:", evt.Err)
+		default:
+			utils.Logger.Println("DEBUG: mode: i3: Unknown event type:", e)
+		}
+	}
+}
+
+func (b *i3Backend) refresh() {
+	mode, err := i3.BindingState()
+	if err != nil {
+		utils.Logger.Println("mode: i3:", err)
+		return
+	}
+	b.set(mode)
+}
+
+// i3 calls the default mode "default", hyprland leaves it empty
+func (b *i3Backend) set(mode string) {
+	if mode == "default" {
+		mode = ""
+	}
+	b.mu.Lock()
+	b.mode = mode
+	b.mu.Unlock()
+}
+
+func (b *i3Backend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *i3Backend) Mode() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.mode
+}
+func (b *i3Backend) Events() <-chan struct{} { return b.sig }
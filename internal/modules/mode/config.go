@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package mode
+
+import (
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/lookup/colors"
+	"github.com/nekorg/pawbar/internal/modules"
+)
+
+func init() {
+	config.RegisterModule("mode", defaultOptions, func(o Options) (modules.Module, error) { return &Module{opts: o}, nil })
+}
+
+// per mode overrides, keyed by the mode (or submap) name
+type ModeOptions struct {
+	Fg     *config.Color  `yaml:"fg"`
+	Bg     *config.Color  `yaml:"bg"`
+	Format *config.Format `yaml:"format"`
+}
+
+type Options struct {
+	Fg      config.Color                      `yaml:"fg"`
+	Bg      config.Color                      `yaml:"bg"`
+	Cursor  config.Cursor                     `yaml:"cursor"`
+	Format  config.Format                     `yaml:"format"`
+	Modes   map[string]ModeOptions            `yaml:"modes"`
+	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
+}
+
+type MouseOptions struct {
+	Fg     *config.Color  `yaml:"fg"`
+	Bg     *config.Color  `yaml:"bg"`
+	Cursor *config.Cursor `yaml:"cursor"`
+	Format *config.Format `yaml:"format"`
+}
+
+func defaultOptions() Options {
+	f, _ := config.NewTemplate(" {{.Mode}} ")
+	urgClr, _ := colors.ParseColor("@urgent")
+	blkClr, _ := colors.ParseColor("@black")
+
+	return Options{
+		Fg:     config.Color(blkClr),
+		Bg:     config.Color(urgClr),
+		Format: config.Format{Template: f},
+		OnClick: config.MouseActions[MouseOptions]{
+			Actions: map[string]*config.MouseAction[MouseOptions]{},
+		},
+	}
+}
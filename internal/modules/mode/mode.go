@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package mode
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/services/i3"
+)
+
+// backend reports the active binding mode (i3/sway) or submap (hyprland),
+// empty in the default one
+type backend interface {
+	Mode() string
+	Events() <-chan struct{}
+}
+
+type Module struct {
+	b       backend
+	receive chan bool
+	send    chan modules.Event
+
+	opts        Options
+	initialOpts Options
+}
+
+func New() modules.Module { return &Module{} }
+
+func (mod *Module) Name() string                                  { return "mode" }
+func (mod *Module) Dependencies() []string                        { return nil }
+func (mod *Module) Channels() (<-chan bool, chan<- modules.Event) { return mod.receive, mod.send }
+
+func (mod *Module) Run() (<-chan bool, chan<- modules.Event, error) {
+	err := mod.selectBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mod.receive = make(chan bool)
+	mod.send = make(chan modules.Event)
+	mod.initialOpts = mod.opts
+
+	go func() {
+		render := mod.b.Events()
+		for {
+			select {
+			case e := <-mod.send:
+				switch ev := e.VaxisEvent.(type) {
+				case vaxis.Mouse:
+					if ev.EventType != vaxis.EventPress {
+						break
+					}
+					btn := config.ButtonName(ev)
+					if mod.opts.OnClick.Dispatch(btn, &mod.initialOpts, &mod.opts) {
+						mod.receive <- true
+					}
+				case modules.FocusIn:
+					if mod.opts.OnClick.HoverIn(&mod.opts) {
+						mod.receive <- true
+					}
+				case modules.FocusOut:
+					if mod.opts.OnClick.HoverOut(&mod.opts) {
+						mod.receive <- true
+					}
+				}
+
+			case <-render:
+				mod.receive <- true
+			}
+		}
+	}()
+
+	return mod.receive, mod.send, nil
+}
+
+func (mod *Module) selectBackend() error {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		svc, ok := hypr.Register()
+		if !ok {
+			return fmt.Errorf("Could not start hypr service.")
+		}
+		mod.b = newHyprBackend(svc)
+	} else if os.Getenv("I3SOCK") != "" || os.Getenv("SWAYSOCK") != "" {
+		svc, ok := i3.Register()
+		if !ok {
+			return fmt.Errorf("Could not start i3 service.")
+		}
+		mod.b = newI3Backend(svc)
+	} else {
+		return fmt.Errorf("Could not find a wm backend for current environment.")
+	}
+
+	return nil
+}
+
+func (mod *Module) Render() []modules.EventCell {
+	mode := mod.b.Mode()
+	if mode == "" {
+		return nil
+	}
+
+	data := struct{ Mode string }{Mode: modules.Escape(mode)}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	fg, bg, format := mod.opts.Fg, mod.opts.Bg, mod.opts.Format
+	if mo, ok := mod.opts.Modes[mode]; ok {
+		if mo.Fg != nil {
+			fg = *mo.Fg
+		}
+		if mo.Bg != nil {
+			bg = *mo.Bg
+		}
+		if mo.Format != nil {
+			format = *mo.Format
+		}
+	}
+
+	style := vaxis.Style{
+		Foreground: fg.Go(),
+		Background: bg.Go(),
+	}
+
+	var buf bytes.Buffer
+	_ = format.Execute(&buf, data)
+
+	rch := modules.Styled(buf.String(), style)
+	r := make([]modules.EventCell, len(rch))
+	for i, ch := range rch {
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+	}
+	return r
+}
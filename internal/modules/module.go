@@ -29,6 +29,8 @@ type EventCell struct {
 	Metadata   string
 	Mod        Module
 	MouseShape vaxis.MouseShape
+	Image      *Image // set on every cell an image covers, see Image.Cells
+	Hidden     bool   // Image stays uploaded but isn't drawn, see Image.Blank
 }
 
 type Module interface {
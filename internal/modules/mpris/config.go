@@ -38,6 +38,8 @@ type Options struct {
 	Play    PlayOptions                       `yaml:"play"`
 	Format  config.Format                     `yaml:"format"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -276,38 +276,42 @@ func (mod *MprisModule) Render() []modules.EventCell {
 		Icon    string
 		Artists string
 		Title   string
-	}{}
+		Status  string
+	}{Status: "Stopped"}
 
 	var tpl config.Format
 
 	switch mod.format {
 	case FormatPlay:
 		data.Icon = string(mod.opts.Play.Icon)
-		data.Artists = artists
-		data.Title = mod.title
+		data.Artists = modules.Escape(artists)
+		data.Title = modules.Escape(mod.title)
+		data.Status = "Playing"
 		tpl = mod.opts.Play.Format
 	case FormatPause:
 		data.Icon = string(mod.opts.Pause.Icon)
-		data.Artists = artists
-		data.Title = mod.title
+		data.Artists = modules.Escape(artists)
+		data.Title = modules.Escape(mod.title)
+		data.Status = "Paused"
 		tpl = mod.opts.Pause.Format
 	default:
 		tpl = mod.opts.Format
 	}
 
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	if err := tpl.Execute(&buf, data); err != nil {
 		return nil
 	}
 
-	chars := vaxis.Characters(buf.String())
+	chars := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(chars))
 	for i, ch := range chars {
 		r[i] = modules.EventCell{
-			C: vaxis.Cell{
-				Character: ch,
-				Style:     style,
-			},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package network
+
+import (
+	"time"
+
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/modules"
+)
+
+func init() {
+	config.RegisterModule("network", defaultOptions, func(o Options) (modules.Module, error) { return &NetworkModule{opts: o}, nil })
+}
+
+type Options struct {
+	Fg     config.Color    `yaml:"fg"`
+	Bg     config.Color    `yaml:"bg"`
+	Cursor config.Cursor   `yaml:"cursor"`
+	Tick   config.Duration `yaml:"tick"`
+	Format config.Format   `yaml:"format"`
+
+	Interface string       `yaml:"interface"` // all but loopback when empty
+	Graph     config.Graph `yaml:"graph"`
+
+	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
+}
+
+type MouseOptions struct {
+	Fg     *config.Color    `yaml:"fg"`
+	Bg     *config.Color    `yaml:"bg"`
+	Cursor *config.Cursor   `yaml:"cursor"`
+	Tick   *config.Duration `yaml:"tick"`
+	Format *config.Format   `yaml:"format"`
+	Graph  *config.Graph    `yaml:"graph"`
+}
+
+func defaultOptions() Options {
+	f0, _ := config.NewTemplate("↓ {{size .Rx}}/s ↑ {{size .Tx}}/s")
+	f1, _ := config.NewTemplate("↓ {{.RxGraph}} ↑ {{.TxGraph}}")
+	return Options{
+		Format: config.Format{Template: f0},
+		Tick:   config.Duration(2 * time.Second),
+		Graph:  config.Graph{Width: 10, Style: config.GraphBlock},
+		OnClick: config.MouseActions[MouseOptions]{
+			Actions: map[string]*config.MouseAction[MouseOptions]{
+				"left": {
+					Configs: []MouseOptions{{Format: &config.Format{Template: f1}}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package network
+
+import (
+	"bytes"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+type NetworkModule struct {
+	receive chan bool
+	send    chan modules.Event
+
+	opts        Options
+	initialOpts Options
+
+	currentTickerInterval time.Duration
+	ticker                *time.Ticker
+
+	// counters of the last sample, rates are taken from the difference
+	lastRx, lastTx uint64
+	lastAt         time.Time
+
+	// bytes per second, Render reads the newest from here
+	rxHistory, txHistory, history *modules.History
+}
+
+func (mod *NetworkModule) Dependencies() []string {
+	return nil
+}
+
+func (mod *NetworkModule) Run() (<-chan bool, chan<- modules.Event, error) {
+	mod.receive = make(chan bool)
+	mod.send = make(chan modules.Event)
+	mod.initialOpts = mod.opts
+	mod.rxHistory = modules.NewHistory(modules.HistorySize)
+	mod.txHistory = modules.NewHistory(modules.HistorySize)
+	mod.history = modules.NewHistory(modules.HistorySize)
+	mod.sample()
+
+	go func() {
+		mod.currentTickerInterval = mod.opts.Tick.Go()
+		mod.ticker = time.NewTicker(mod.currentTickerInterval)
+		defer mod.ticker.Stop()
+		for {
+			select {
+			case <-mod.ticker.C:
+				mod.sample()
+				mod.receive <- true
+			case e := <-mod.send:
+				switch ev := e.VaxisEvent.(type) {
+				case vaxis.Mouse:
+					if ev.EventType != vaxis.EventPress {
+						break
+					}
+					btn := config.ButtonName(ev)
+					if mod.opts.OnClick.Dispatch(btn, &mod.initialOpts, &mod.opts) {
+						mod.receive <- true
+					}
+					mod.ensureTickInterval()
+
+				case modules.FocusIn:
+					if mod.opts.OnClick.HoverIn(&mod.opts) {
+						mod.receive <- true
+					}
+					mod.ensureTickInterval()
+
+				case modules.FocusOut:
+					if mod.opts.OnClick.HoverOut(&mod.opts) {
+						mod.receive <- true
+					}
+					mod.ensureTickInterval()
+				}
+			}
+		}
+	}()
+
+	return mod.receive, mod.send, nil
+}
+
+func (mod *NetworkModule) ensureTickInterval() {
+	if mod.opts.Tick.Go() != mod.currentTickerInterval {
+		mod.currentTickerInterval = mod.opts.Tick.Go()
+		mod.ticker.Reset(mod.currentTickerInterval)
+	}
+}
+
+// counters sums the byte counters of the configured interface, or of every
+// interface but loopback
+func (mod *NetworkModule) counters() (rx, tx uint64, err error) {
+	stats, err := net.IOCounters(true)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, s := range stats {
+		if mod.opts.Interface != "" && s.Name != mod.opts.Interface {
+			continue
+		}
+		if mod.opts.Interface == "" && s.Name == "lo" {
+			continue
+		}
+		rx += s.BytesRecv
+		tx += s.BytesSent
+	}
+	return rx, tx, nil
+}
+
+func (mod *NetworkModule) sample() {
+	rx, tx, err := mod.counters()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	prevRx, prevTx, prevAt := mod.lastRx, mod.lastTx, mod.lastAt
+	mod.lastRx, mod.lastTx, mod.lastAt = rx, tx, now
+
+	// the first sample only sets the baseline
+	if prevAt.IsZero() {
+		return
+	}
+	secs := now.Sub(prevAt).Seconds()
+	if secs <= 0 {
+		return
+	}
+	// counters go back when an interface goes away, count that as idle
+	var rxRate, txRate float64
+	if rx >= prevRx {
+		rxRate = float64(rx-prevRx) / secs
+	}
+	if tx >= prevTx {
+		txRate = float64(tx-prevTx) / secs
+	}
+	mod.rxHistory.Push(rxRate)
+	mod.txHistory.Push(txRate)
+	mod.history.Push(rxRate + txRate)
+}
+
+func (mod *NetworkModule) Render() []modules.EventCell {
+	style := vaxis.Style{
+		Foreground: mod.opts.Fg.Go(),
+		Background: mod.opts.Bg.Go(),
+	}
+
+	data := struct {
+		Rx, Tx                  float64
+		Interface               string
+		Graph, RxGraph, TxGraph string
+	}{
+		mod.rxHistory.Last(), mod.txHistory.Last(),
+		mod.opts.Interface,
+		mod.opts.Graph.Render(mod.history.Values()),
+		mod.opts.Graph.Render(mod.rxHistory.Values()),
+		mod.opts.Graph.Render(mod.txHistory.Values()),
+	}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	mod.opts.Format.Execute(&buf, data)
+
+	rch := modules.Styled(buf.String(), style)
+	r := make([]modules.EventCell, len(rch))
+	for i, ch := range rch {
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+	}
+	return r
+}
+
+func (mod *NetworkModule) Channels() (<-chan bool, chan<- modules.Event) {
+	return mod.receive, mod.send
+}
+
+func (mod *NetworkModule) Name() string {
+	return "network"
+}
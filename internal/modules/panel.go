@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package modules
+
+import (
+	"sync"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/output"
+)
+
+// size of the bar in cells and pixels, kept up to date by the main loop so
+// modules can place popups relative to a cell
+var (
+	panelMu      sync.RWMutex
+	panelSize    vaxis.Resize
+	panelMonitor output.Monitor
+)
+
+func SetPanelSize(r vaxis.Resize) {
+	panelMu.Lock()
+	defer panelMu.Unlock()
+	panelSize = r
+}
+
+func PanelSize() vaxis.Resize {
+	panelMu.RLock()
+	defer panelMu.RUnlock()
+	return panelSize
+}
+
+// CellOrigin returns the pixel position of the top left corner of a cell,
+// relative to the bar.
+func CellOrigin(col, row int) (x, y int) {
+	r := PanelSize()
+	if r.Cols == 0 || r.Rows == 0 {
+		return 0, 0
+	}
+	return col * r.XPixel / r.Cols, row * r.YPixel / r.Rows
+}
+
+// SetPanelMonitor records the output the bar is on, see output.Current.
+func SetPanelMonitor(m output.Monitor) {
+	panelMu.Lock()
+	defer panelMu.Unlock()
+	panelMonitor = m
+}
+
+func PanelMonitor() output.Monitor {
+	panelMu.RLock()
+	defer panelMu.RUnlock()
+	return panelMonitor
+}
+
+// PopupOrigin returns where a popup opened from col should go: right under
+// the bar, in the logical pixels layer-shell margins are measured in.
+func PopupOrigin(col int) (x, y int) {
+	x, _ = CellOrigin(col, 0)
+	m := PanelMonitor()
+	return m.Logical(x), m.Logical(PanelSize().YPixel)
+}
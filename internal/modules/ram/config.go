@@ -38,8 +38,11 @@ type Options struct {
 	Scale config.Scale `yaml:"unit"`
 
 	Thresholds []ThresholdOptions `yaml:"thresholds"`
+	Graph      config.Graph       `yaml:"graph"`
 
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -52,6 +55,7 @@ type MouseOptions struct {
 
 	UseSI *bool         `yaml:"use_si"`
 	Scale *config.Scale `yaml:"scale"`
+	Graph *config.Graph `yaml:"graph"`
 }
 
 func defaultOptions() Options {
@@ -65,6 +69,7 @@ func defaultOptions() Options {
 		Tick:   config.Duration(10 * time.Second),
 		UseSI:  false,
 		Icon:   config.Icon(icon),
+		Graph:  config.Graph{Width: 10, Style: config.GraphBlock, Max: 100},
 		Thresholds: []ThresholdOptions{
 			{
 				Percent:   80,
@@ -95,6 +95,8 @@ type RamModule struct {
 
 	currentTickerInterval time.Duration
 	ticker                *time.Ticker
+
+	history *modules.History
 }
 
 func (mod *RamModule) Dependencies() []string {
@@ -105,6 +107,8 @@ func (mod *RamModule) Run() (<-chan bool, chan<- modules.Event, error) {
 	mod.receive = make(chan bool)
 	mod.send = make(chan modules.Event)
 	mod.initialOpts = mod.opts
+	mod.history = modules.NewHistory(modules.HistorySize)
+	mod.sample()
 
 	go func() {
 		mod.currentTickerInterval = mod.opts.Tick.Go()
@@ -113,6 +117,7 @@ func (mod *RamModule) Run() (<-chan bool, chan<- modules.Event, error) {
 		for {
 			select {
 			case <-mod.ticker.C:
+				mod.sample()
 				mod.receive <- true
 			case e := <-mod.send:
 				switch ev := e.VaxisEvent.(type) {
@@ -152,6 +157,14 @@ func (mod *RamModule) ensureTickInterval() {
 	}
 }
 
+func (mod *RamModule) sample() {
+	v, err := virtualMemory()
+	if err != nil {
+		return
+	}
+	mod.history.Push(v.UsedPercent)
+}
+
 func pickThreshold(p int, th []ThresholdOptions) *ThresholdOptions {
 	for _, t := range th {
 		matchUp := t.Direction.IsUp() && p >= t.Percent.Go()
@@ -198,23 +211,29 @@ func (mod *RamModule) Render() []modules.EventCell {
 		style.Background = mod.opts.Bg.Go()
 	}
 
-	var buf bytes.Buffer
-
-	err = mod.opts.Format.Execute(&buf, struct {
+	data := struct {
 		Used, Free, Total        float64
 		UsedPercent, FreePercent int
-		Unit, Icon               string
+		Unit, Icon, Graph        string
 	}{
 		usedAbs, freeAbs, totalAbs,
 		usedPercent, freePercent,
 		unit.Name, mod.opts.Icon.Go(),
-	})
+		mod.opts.Graph.Render(mod.history.Values()),
+	}
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	err = mod.opts.Format.Execute(&buf, data)
 
-	rch := vaxis.Characters(buf.String())
+	rch := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(rch))
 
 	for i, ch := range rch {
-		r[i] = modules.EventCell{C: vaxis.Cell{Character: ch, Style: style}, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
+		r[i] = modules.EventCell{C: ch, Mod: mod, MouseShape: mod.opts.Cursor.Go()}
 	}
 	return r
 }
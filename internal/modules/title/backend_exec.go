@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package title
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/wmexec"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// the command prints {"window": {"class": "kitty", "title": "~"}} whenever
+// the focused window changes, null when nothing is focused. lines without
+// "window" are skipped, so one command can feed ws too.
+type execBackend struct {
+	s     *wmexec.Stream
+	lines <-chan []byte
+	mu    sync.RWMutex
+	win   Window
+	sig   chan struct{}
+}
+
+func newExecBackend(s *wmexec.Stream) backend {
+	b := &execBackend{
+		s:     s,
+		lines: s.Subscribe(),
+		sig:   make(chan struct{}, 1),
+	}
+	go b.loop()
+	return b
+}
+
+func (b *execBackend) loop() {
+	for line := range b.lines {
+		var state map[string]json.RawMessage
+		if err := json.Unmarshal(line, &state); err != nil {
+			utils.Logger.Println("title: exec:", err)
+			continue
+		}
+		raw, ok := state["window"]
+		if !ok {
+			continue
+		}
+
+		var w struct {
+			Class string `json:"class"`
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(raw, &w); err != nil {
+			utils.Logger.Println("title: exec:", err)
+			continue
+		}
+
+		b.mu.Lock()
+		b.win = Window{Title: w.Title, Class: w.Class}
+		b.mu.Unlock()
+		b.signal()
+	}
+}
+
+func (b *execBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *execBackend) Window() Window {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.win
+}
+func (b *execBackend) Events() <-chan struct{} { return b.sig }
@@ -7,9 +7,8 @@
 package title
 
 import (
-	"strings"
-
 	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/utils"
 )
 
 type hyprBackend struct {
@@ -27,17 +26,7 @@ func newHyprBackend(s *hypr.Service) backend {
 		sig: make(chan struct{}, 1),
 	}
 
-	activews := hypr.GetActiveWorkspace()
-	clients := hypr.GetClients()
-
-	b.class = ""
-	for _, c := range clients {
-		if c.Address == activews.Lastwindow {
-			b.class = c.Class
-		}
-	}
-
-	b.title = hypr.GetActiveWorkspace().Lastwindowtitle
+	b.refresh()
 	b.svc.RegisterChannel("activewindow", b.ev)
 	go b.loop()
 	return b
@@ -45,11 +34,32 @@ func newHyprBackend(s *hypr.Service) backend {
 
 func (b *hyprBackend) loop() {
 	for e := range b.ev {
-		b.class, b.title, _ = strings.Cut(e.Data, ",")
+		ev, err := e.Parse()
+		if err != nil {
+			utils.Logger.Println("title:", err)
+			continue
+		}
+
+		switch ev := ev.(type) {
+		case hypr.ActiveWindowEvent:
+			b.class, b.title = ev.Class, ev.Title
+		case hypr.ResyncEvent:
+			b.refresh()
+		}
 		b.signal()
 	}
 }
 
+// asks hyprland for the focused window instead of waiting for an event
+func (b *hyprBackend) refresh() {
+	w, err := hypr.ActiveWindow()
+	if err != nil {
+		utils.Logger.Println("title:", err)
+		return
+	}
+	b.class, b.title = w.Class, w.Title
+}
+
 func (b *hyprBackend) signal() {
 	select {
 	case b.sig <- struct{}{}:
@@ -14,7 +14,6 @@ import (
 type i3Backend struct {
 	svc      *i3.Service
 	ev       chan interface{}
-	ev2      chan interface{}
 	instance string
 	title    string
 	sig      chan struct{}
@@ -24,40 +23,41 @@ func newI3Backend(s *i3.Service) backend {
 	b := &i3Backend{
 		svc: s,
 		ev:  make(chan interface{}),
-		ev2: make(chan interface{}),
 		sig: make(chan struct{}, 2),
 	}
 
-	b.instance, b.title = i3.GetTitleClass()
+	b.refresh()
 
-	b.svc.RegisterChannel("activeWindow", b.ev)
-	b.svc.RegisterChannel("workspaces", b.ev2)
+	b.svc.RegisterChannel(i3.EventWindow, b.ev)
+	b.svc.RegisterChannel(i3.EventWorkspace, b.ev)
 
 	go b.loop()
 	return b
 }
 
 func (b *i3Backend) loop() {
-	for {
-		select {
-		case e := <-b.ev:
-			if _, ok := e.(i3.I3WEvent); ok {
-				b.instance, b.title = i3.GetTitleClass()
-				b.signal()
-			} else {
-				utils.Logger.Println("DEBUG: ws: i3: Unknown event on window event channel:", e)
-			}
-		case e := <-b.ev2:
-			if _, ok := e.(i3.I3Event); ok {
-				b.instance, b.title = i3.GetTitleClass()
-				b.signal()
-			} else {
-				utils.Logger.Println("DEBUG: ws: i3: Unknown event type on workspace event channel:", e)
-			}
+	for e := range b.ev {
+		switch evt := e.(type) {
+		case i3.I3WEvent, i3.I3Event, i3.ResyncEvent:
+			b.refresh()
+			b.signal()
+		case i3.ErrorEvent:
+			utils.Logger.Println("title: i3:", evt.Err)
+		default:
+			utils.Logger.Println("DEBUG: title: i3: Unknown event type:", e)
 		}
 	}
 }
 
+func (b *i3Backend) refresh() {
+	instance, title, err := i3.GetTitleClass()
+	if err != nil {
+		utils.Logger.Println("title: i3:", err)
+		return
+	}
+	b.instance, b.title = instance, title
+}
+
 func (b *i3Backend) signal() {
 	select {
 	case b.sig <- struct{}{}:
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package title
+
+import (
+	"github.com/nekorg/pawbar/internal/services/niri"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+type niriBackend struct {
+	svc *niri.Service
+	ev  chan interface{}
+	sig chan struct{}
+}
+
+func newNiriBackend(s *niri.Service) backend {
+	b := &niriBackend{
+		svc: s,
+		ev:  make(chan interface{}),
+		sig: make(chan struct{}, 1),
+	}
+
+	for _, e := range []string{
+		niri.EventWindowFocusChanged, niri.EventWindowOpenedOrChanged,
+		niri.EventWindowClosed, niri.EventWindowsChanged,
+	} {
+		b.svc.RegisterChannel(e, b.ev)
+	}
+
+	go b.loop()
+	return b
+}
+
+func (b *niriBackend) loop() {
+	for e := range b.ev {
+		switch evt := e.(type) {
+		case niri.Event, niri.ResyncEvent:
+			b.signal()
+		case niri.ErrorEvent:
+			utils.Logger.Println("title: niri:", evt.Err)
+		}
+	}
+}
+
+func (b *niriBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *niriBackend) Window() Window {
+	w, _ := b.svc.FocusedWindow()
+	return Window{Title: w.Title, Class: w.AppId}
+}
+func (b *niriBackend) Events() <-chan struct{} { return b.sig }
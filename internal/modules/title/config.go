@@ -23,12 +23,19 @@ type DataOptions struct {
 }
 
 type Options struct {
-	Fg      config.Color                      `yaml:"fg"`
-	Bg      config.Color                      `yaml:"bg"`
-	Cursor  config.Cursor                     `yaml:"cursor"`
-	Title   DataOptions                       `yaml:"title"`
-	Class   DataOptions                       `yaml:"class"`
+	Fg     config.Color  `yaml:"fg"`
+	Bg     config.Color  `yaml:"bg"`
+	Cursor config.Cursor `yaml:"cursor"`
+	Title  DataOptions   `yaml:"title"`
+	Class  DataOptions   `yaml:"class"`
+
+	// auto picks by the environment, exec runs the command in exec
+	Backend config.Backend `yaml:"backend"`
+	Exec    []string       `yaml:"exec"`
+
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -9,13 +9,14 @@ package title
 import (
 	"bytes"
 	"fmt"
-	"os"
 
 	"git.sr.ht/~rockorager/vaxis"
 	"github.com/nekorg/pawbar/internal/config"
 	"github.com/nekorg/pawbar/internal/modules"
 	"github.com/nekorg/pawbar/internal/services/hypr"
 	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/services/niri"
+	"github.com/nekorg/pawbar/internal/services/wmexec"
 )
 
 type Window struct {
@@ -89,19 +90,36 @@ func (mod *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 }
 
 func (mod *Module) selectBackend() error {
-	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+	backend := mod.opts.Backend.Resolve(len(mod.opts.Exec) > 0)
+	switch backend {
+	case config.BackendHypr:
 		svc, ok := hypr.Register()
 		if !ok {
 			return fmt.Errorf("Could not start hypr service.")
 		}
 		mod.b = newHyprBackend(svc)
-	} else if os.Getenv("I3SOCK") != "" || os.Getenv("SWAYSOCK") != "" {
+	case config.BackendI3:
 		svc, ok := i3.Register()
 		if !ok {
 			return fmt.Errorf("Could not start i3 service.")
 		}
 		mod.b = newI3Backend(svc)
-	} else {
+	case config.BackendNiri:
+		svc, ok := niri.Register()
+		if !ok {
+			return fmt.Errorf("Could not start niri service.")
+		}
+		mod.b = newNiriBackend(svc)
+	case config.BackendExec:
+		if len(mod.opts.Exec) == 0 {
+			return fmt.Errorf("The exec backend needs a command in exec.")
+		}
+		s, err := wmexec.Register(mod.opts.Exec)
+		if err != nil {
+			return fmt.Errorf("Could not start exec backend: %w", err)
+		}
+		mod.b = newExecBackend(s)
+	default:
 		return fmt.Errorf("Could not find a wm backend for current environment.")
 	}
 
@@ -110,6 +128,10 @@ func (mod *Module) selectBackend() error {
 
 func (mod *Module) Render() []modules.EventCell {
 	win := mod.b.Window()
+	if mod.opts.Hidden(win) {
+		return nil
+	}
+
 	var cells []modules.EventCell
 
 	if win.Class != "" {
@@ -120,15 +142,12 @@ func (mod *Module) Render() []modules.EventCell {
 
 		var buf bytes.Buffer
 		_ = mod.opts.Class.Format.Execute(&buf, struct{ Class string }{
-			Class: " " + win.Class + " ",
+			Class: " " + modules.Escape(win.Class) + " ",
 		})
 
-		for _, ch := range vaxis.Characters(buf.String()) {
+		for _, ch := range modules.Styled(buf.String(), style) {
 			cells = append(cells, modules.EventCell{
-				C: vaxis.Cell{
-					Character: ch,
-					Style:     style,
-				},
+				C:          ch,
 				Mod:        mod,
 				MouseShape: vaxis.MouseShapeDefault,
 			})
@@ -143,15 +162,12 @@ func (mod *Module) Render() []modules.EventCell {
 
 		var buf bytes.Buffer
 		_ = mod.opts.Title.Format.Execute(&buf, struct{ Title string }{
-			Title: win.Title,
+			Title: modules.Escape(win.Title),
 		})
 		cells = append(cells, modules.EventCell{C: vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}}, Mod: mod})
-		for _, ch := range vaxis.Characters(buf.String()) {
+		for _, ch := range modules.Styled(buf.String(), style) {
 			cells = append(cells, modules.EventCell{
-				C: vaxis.Cell{
-					Character: ch,
-					Style:     style,
-				},
+				C:          ch,
 				Mod:        mod,
 				MouseShape: vaxis.MouseShapeDefault,
 			})
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package tray
+
+import (
+	"path"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/config"
+	"github.com/nekorg/pawbar/internal/lookup/colors"
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/services/sni"
+)
+
+func init() {
+	config.RegisterModule("tray", defaultOptions, func(o Options) (modules.Module, error) { return &Module{opts: o}, nil })
+}
+
+type AttentionOptions struct {
+	Fg    config.Color `yaml:"fg"`
+	Bg    config.Color `yaml:"bg"`
+	Blink bool         `yaml:"blink"`
+}
+
+// per item overrides, keyed by the item's Id
+type ItemOptions struct {
+	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+}
+
+type Options struct {
+	Fg     config.Color  `yaml:"fg"`
+	Bg     config.Color  `yaml:"bg"`
+	Cursor config.Cursor `yaml:"cursor"`
+
+	// glob patterns matched against Id and Title
+	Hide        []string `yaml:"hide"`
+	HidePassive bool     `yaml:"hide_passive"`
+	// items matching these come first, in this order
+	Order []string `yaml:"order"`
+
+	Categories     []string `yaml:"categories"`
+	GroupSeparator string   `yaml:"group_separator"`
+
+	IconSize int  `yaml:"icon_size"`
+	Spacing  *int `yaml:"spacing"`
+
+	Attention AttentionOptions                  `yaml:"attention"`
+	Items     map[string]ItemOptions            `yaml:"items"`
+	OnClick   config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
+}
+
+type MouseOptions struct {
+	Fg       *config.Color  `yaml:"fg"`
+	Bg       *config.Color  `yaml:"bg"`
+	Cursor   *config.Cursor `yaml:"cursor"`
+	IconSize *int           `yaml:"icon_size"`
+}
+
+func defaultOptions() Options {
+	urgClr, _ := colors.ParseColor("@urgent")
+	spacing := 1
+	return Options{
+		Cursor: config.Cursor(vaxis.MouseShapeClickable),
+		Categories: []string{
+			string(sni.CatAppStatus),
+			string(sni.CatComm),
+			string(sni.CatSysService),
+			string(sni.CatHardware),
+		},
+		IconSize: iconCells,
+		Spacing:  &spacing,
+		Attention: AttentionOptions{
+			Fg: config.Color(urgClr),
+		},
+		OnClick: config.MouseActions[MouseOptions]{},
+	}
+}
+
+func match(patterns []string, it sni.Item) int {
+	for i, p := range patterns {
+		p = strings.ToLower(p)
+		for _, s := range []string{it.Id, it.Title} {
+			if ok, _ := path.Match(p, strings.ToLower(s)); ok && s != "" {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return len(list)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package tray
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codelif/gorsvg"
+	"github.com/codelif/xdgicons"
+	"github.com/codelif/xdgicons/missing"
+	"github.com/nekorg/pawbar/internal/lookup/colors"
+	"github.com/nekorg/pawbar/internal/modules"
+	"github.com/nekorg/pawbar/internal/services/sni"
+	"github.com/nekorg/pawbar/internal/utils"
+	"golang.org/x/image/draw"
+)
+
+const (
+	iconSize  = 48 // px, what we ask themes and svgs for
+	iconCells = 2  // default width, cells are about twice as tall as wide
+)
+
+var iconLookup = xdgicons.NewIconLookupWithConfig(xdgicons.LookupConfig{FallbackTheme: "Adwaita"})
+
+// resolves the icon of it into an image, following the spec: the attention
+// icon replaces the normal one while Status is NeedsAttention, and the
+// overlay is drawn over the bottom right corner. names are looked up in the
+// item's own IconThemePath first, then the icon theme; pixmaps are the
+// fallback. the same icon returns the same *modules.Image across renders so
+// it is only uploaded once.
+func (m *Module) icon(it sni.Item) *modules.Image {
+	name, pix := it.IconName, it.IconPixmap
+	if it.Status == "NeedsAttention" && (it.Attention != "" || len(it.AttentionPixmap) > 0) {
+		name, pix = it.Attention, it.AttentionPixmap
+	}
+	if name == "" && len(pix) == 0 {
+		return nil
+	}
+
+	cells := max(m.opts.IconSize, 1)
+	key := fmt.Sprintf("%d|%s|%s", cells,
+		iconKey(it.IconThemeDir, name, pix),
+		iconKey(it.IconThemeDir, it.OverlayName, it.OverlayPixmap))
+	if im, ok := m.icons[key]; ok {
+		m.nextIcons[key] = im
+		return im
+	}
+
+	img := loadIcon(it.IconThemeDir, name, pix)
+	if img == nil {
+		img = missing.GenerateMissingIcon(iconSize, fgColor())
+	}
+	if ov := loadIcon(it.IconThemeDir, it.OverlayName, it.OverlayPixmap); ov != nil {
+		img = overlay(img, ov)
+	}
+
+	im := modules.NewImage(img, cells)
+	m.nextIcons[key] = im
+	return im
+}
+
+func iconKey(dir, name string, pix []sni.Pixmap) string {
+	if name != "" {
+		return "n:" + dir + "/" + name
+	}
+	if len(pix) == 0 {
+		return ""
+	}
+	h := fnv.New64a()
+	for _, p := range pix {
+		fmt.Fprintf(h, "%dx%d", p.Width, p.Height)
+		h.Write(p.Data)
+	}
+	return fmt.Sprintf("p:%x", h.Sum64())
+}
+
+func loadIcon(dir, name string, pix []sni.Pixmap) image.Image {
+	if name != "" {
+		img, err := loadNamed(dir, name)
+		if err == nil {
+			return img
+		}
+		utils.Logger.Printf("tray: icon %q: %v\n", name, err)
+	}
+	return sni.PixmapImage(pix)
+}
+
+func loadNamed(dir, name string) (image.Image, error) {
+	// some items send a full path instead of a name
+	if filepath.IsAbs(name) {
+		return decodeFile(name, strings.HasSuffix(name, "-symbolic.svg"))
+	}
+
+	if dir != "" {
+		if p := findInDir(dir, name); p != "" {
+			return decodeFile(p, strings.HasSuffix(name, "-symbolic"))
+		}
+	}
+
+	icon, err := iconLookup.FindIcon(name, iconSize, 1)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFile(icon.Path, strings.HasSuffix(icon.Name, "-symbolic"))
+}
+
+// IconThemePath is either a flat directory of icons or a small theme
+// (hicolor/48x48/apps/...), the largest match wins
+func findInDir(dir, name string) string {
+	best, bestSize := "", -1
+	for _, pattern := range []string{"%s/%s.%s", "%s/*/*/*/%s.%s", "%s/*/*/%s.%s"} {
+		for _, ext := range []string{"svg", "png"} {
+			matches, _ := filepath.Glob(fmt.Sprintf(pattern, dir, name, ext))
+			for _, p := range matches {
+				size := 0
+				if ext == "svg" {
+					size = 1 << 16 // scales to anything
+				} else if fi, err := os.Stat(p); err == nil {
+					size = int(fi.Size())
+				}
+				if size > bestSize {
+					best, bestSize = p, size
+				}
+			}
+		}
+	}
+	return best
+}
+
+func decodeFile(path string, symbolic bool) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		if symbolic {
+			return gorsvg.DecodeWithColor(f, iconSize, iconSize, fgColor())
+		}
+		return gorsvg.Decode(f, iconSize, iconSize)
+	case ".png":
+		return png.Decode(f)
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", path)
+	}
+}
+
+func overlay(base, ov image.Image) image.Image {
+	b := base.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), base, b.Min, draw.Src)
+
+	half := image.Rect(b.Dx()/2, b.Dy()/2, b.Dx(), b.Dy())
+	draw.ApproxBiLinear.Scale(out, half, ov, ov.Bounds(), draw.Over, nil)
+	return out
+}
+
+func fgColor() color.Color {
+	r, g, b := colors.RGB(0)
+	return color.NRGBA{R: r, G: g, B: b, A: 255}
+}
@@ -7,29 +7,37 @@
 package tray
 
 import (
-	"bytes"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"git.sr.ht/~rockorager/vaxis"
 	"github.com/nekorg/pawbar/internal/config"
 	"github.com/nekorg/pawbar/internal/modules"
 	"github.com/nekorg/pawbar/internal/services/sni"
+	"github.com/nekorg/pawbar/internal/utils"
 	"github.com/nekorg/pawbar/pkg/dbusmenukitty"
-	"gopkg.in/yaml.v3"
 )
 
-func init() {
-	config.Register("tray", func(n *yaml.Node) (modules.Module, error) {
-		// no options yet
-		return &Module{}, nil
-	})
-}
+const blinkInterval = 500 * time.Millisecond
 
 type Module struct {
-	svc      *sni.Service
-	receive  chan bool
-	send     chan modules.Event
+	svc     *sni.Service
+	receive chan bool
+	send    chan modules.Event
+
+	opts        Options
+	initialOpts Options
+
+	mu       sync.Mutex
 	lastList []sni.Item
+	shown    []sni.Item // what the last render drew, in order
+	blinkOff bool
+
+	icons     map[string]*modules.Image
+	nextIcons map[string]*modules.Image
 }
 
 func (m *Module) Name() string                                  { return "tray" }
@@ -44,17 +52,26 @@ func (m *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 	m.svc = svc
 	m.receive = make(chan bool, 4)
 	m.send = make(chan modules.Event, 8)
+	m.initialOpts = m.opts
 
 	// Subscribe to SNI updates
 	evs := svc.IssueListener()
 	m.lastList = svc.Items()
 
 	go func() {
+		blink := time.NewTicker(blinkInterval)
+		defer blink.Stop()
 		for {
 			select {
 			case <-evs:
+				m.mu.Lock()
 				m.lastList = svc.Items()
+				m.mu.Unlock()
 				m.receive <- true
+			case <-blink.C:
+				if m.toggleBlink() {
+					m.receive <- true
+				}
 			case e := <-m.send:
 				switch ev := e.VaxisEvent.(type) {
 				case vaxis.Mouse:
@@ -62,28 +79,27 @@ func (m *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 						break
 					}
 					// Which “cell” (item) did we click? We encode metadata = index
-					idx, _ := strconv.Atoi(e.Cell.Metadata)
-					if idx < 0 || idx >= len(m.lastList) {
+					idx, err := strconv.Atoi(e.Cell.Metadata)
+					m.mu.Lock()
+					if err != nil || idx < 0 || idx >= len(m.shown) {
+						m.mu.Unlock()
 						break
 					}
-					item := m.lastList[idx]
-					switch ev.Button {
-					case vaxis.MouseLeftButton:
-						_ = svc.Activate(item, int32(ev.XPixel), int32(ev.YPixel))
-					case vaxis.MouseMiddleButton:
-						_ = svc.SecondaryActivate(item, int32(ev.XPixel), int32(ev.YPixel))
-					case vaxis.MouseRightButton:
-						// Prefer item.ContextMenu if it exists; if menu path is published, open our TUI popup
-						if item.MenuPath != "" {
-							// TODO: this assumes 2x scale, use pkg/monitor to determine correct scale.
-							go dbusmenukitty.LaunchMenu(ev.XPixel/2, ev.YPixel/2)
-						} else {
-							_ = svc.ContextMenu(item, int32(ev.XPixel), int32(ev.YPixel))
-						}
-					case vaxis.MouseWheelUp:
-						_ = svc.Scroll(item, +120, "vertical")
-					case vaxis.MouseWheelDown:
-						_ = svc.Scroll(item, -120, "vertical")
+					item := m.shown[idx]
+					m.mu.Unlock()
+
+					if m.dispatch(item, ev) {
+						m.receive <- true
+					}
+
+				case modules.FocusIn:
+					if m.opts.OnClick.HoverIn(&m.opts) {
+						m.receive <- true
+					}
+
+				case modules.FocusOut:
+					if m.opts.OnClick.HoverOut(&m.opts) {
+						m.receive <- true
 					}
 				}
 			}
@@ -93,44 +109,199 @@ func (m *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 	return m.receive, m.send, nil
 }
 
-func (m *Module) Render() []modules.EventCell {
-	list := m.lastList
-	if len(list) == 0 {
-		return nil
+// flips the blink phase while something needs attention, reports whether a
+// render is needed
+func (m *Module) toggleBlink() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attention := false
+	for _, it := range m.shown {
+		attention = attention || it.Status == "NeedsAttention"
+	}
+	if m.opts.Attention.Blink && attention {
+		m.blinkOff = !m.blinkOff
+		return true
 	}
-	// MVP text form: [icon-like]Title or Id
-	// You can swap ’labelFor’ to prefer IconName, Title, Id, etc.
-	labelFor := func(it sni.Item) string {
-		if it.IconName != "" {
-			return it.IconName // simple and compact
+	if m.blinkOff {
+		m.blinkOff = false
+		return true
+	}
+	return false
+}
+
+// onmouse of the item wins, then the module's, then the usual SNI action.
+// returns true if options changed
+func (m *Module) dispatch(item sni.Item, ev vaxis.Mouse) bool {
+	btn := config.ButtonName(ev)
+	if io, ok := m.opts.Items[item.Id]; ok {
+		if _, ok := io.OnClick.Actions[btn]; ok {
+			return io.OnClick.Dispatch(btn, &m.initialOpts, &m.opts)
+		}
+	}
+	if _, ok := m.opts.OnClick.Actions[btn]; ok {
+		return m.opts.OnClick.Dispatch(btn, &m.initialOpts, &m.opts)
+	}
+
+	switch ev.Button {
+	case vaxis.MouseLeftButton:
+		// items that are only a menu want it on left click too
+		if item.ItemIsMenu && item.MenuPath != "" {
+			go m.openMenu(item, ev)
+			break
+		}
+		_ = m.svc.Activate(item, int32(ev.XPixel), int32(ev.YPixel))
+	case vaxis.MouseMiddleButton:
+		_ = m.svc.SecondaryActivate(item, int32(ev.XPixel), int32(ev.YPixel))
+	case vaxis.MouseRightButton:
+		// open our own popup if the item publishes a dbusmenu, otherwise let it draw one
+		if item.MenuPath != "" {
+			go m.openMenu(item, ev)
+		} else {
+			_ = m.svc.ContextMenu(item, int32(ev.XPixel), int32(ev.YPixel))
 		}
-		if it.Title != "" {
-			return it.Title
+	case vaxis.MouseWheelUp:
+		_ = m.svc.Scroll(item, +120, "vertical")
+	case vaxis.MouseWheelDown:
+		_ = m.svc.Scroll(item, -120, "vertical")
+	}
+	return false
+}
+
+// opens the item's menu right under the clicked cell
+func (m *Module) openMenu(item sni.Item, ev vaxis.Mouse) {
+	x, y := modules.PopupOrigin(ev.Col)
+	mon := modules.PanelMonitor()
+
+	err := dbusmenukitty.LaunchMenu(item.BusName, string(item.MenuPath), dbusmenukitty.Placement{
+		X:      x,
+		Y:      y,
+		Output: mon.Name,
+		Scale:  mon.Scale.X,
+	})
+	if err != nil {
+		utils.Logger.Printf("tray: %v, falling back to ContextMenu\n", err)
+		_ = m.svc.ContextMenu(item, int32(ev.XPixel), int32(ev.YPixel))
+	}
+}
+
+// applies hide, hide_passive, order and category grouping
+func (m *Module) visible(list []sni.Item) []sni.Item {
+	out := make([]sni.Item, 0, len(list))
+	for _, it := range list {
+		if m.opts.HidePassive && it.Status == "Passive" {
+			continue
 		}
-		if it.Id != "" {
-			return it.Id
+		if match(m.opts.Hide, it) >= 0 {
+			continue
 		}
-		return "?"
+		out = append(out, it)
+	}
+
+	pinned := func(it sni.Item) int {
+		if i := match(m.opts.Order, it); i >= 0 {
+			return i
+		}
+		return len(m.opts.Order)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := pinned(out[i]), pinned(out[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return indexOf(m.opts.Categories, string(out[i].Category)) < indexOf(m.opts.Categories, string(out[j].Category))
+	})
+	return out
+}
+
+func (m *Module) Render() []modules.EventCell {
+	m.mu.Lock()
+	list := m.visible(m.lastList)
+	m.shown = list
+	blinkOff := m.blinkOff
+	m.mu.Unlock()
+
+	if len(list) == 0 || m.opts.Hidden(struct{ Count int }{len(list)}) {
+		return nil
+	}
+
+	// only icons used by this render are kept, the renderer frees the rest
+	m.nextIcons = make(map[string]*modules.Image)
+	defer func() { m.icons = m.nextIcons }()
+
+	base := vaxis.Style{
+		Foreground: m.opts.Fg.Go(),
+		Background: m.opts.Bg.Go(),
+	}
+	shape := m.opts.Cursor.Go()
+
+	spacing := 1
+	if m.opts.Spacing != nil {
+		spacing = max(*m.opts.Spacing, 0)
+	}
+	gap := func(n int) []modules.EventCell {
+		return m.text(strings.Repeat(" ", n), base, "", shape)
 	}
 
 	var out []modules.EventCell
-	style := vaxis.Style{} // inherit bar defaults
 	for i, it := range list {
-		// Add a space separator between items
 		if i != 0 {
-			out = append(out, modules.ECSPACE)
+			out = append(out, gap(spacing)...)
+			// pinned items are kept out of groups
+			if m.opts.GroupSeparator != "" && it.Category != list[i-1].Category && match(m.opts.Order, it) < 0 {
+				out = append(out, m.text(m.opts.GroupSeparator, base, "", shape)...)
+				out = append(out, gap(spacing)...)
+			}
+		}
+		meta := strconv.Itoa(i) // index for click routing
+
+		style := base
+		attention := it.Status == "NeedsAttention"
+		if attention {
+			style.Foreground = m.opts.Attention.Fg.Go()
+			style.Background = m.opts.Attention.Bg.Go()
+		}
+
+		im := m.icon(it)
+		if attention && blinkOff {
+			// keep the space and the upload, just don't draw the icon
+			if im != nil {
+				out = append(out, im.Blank(m, style, shape, meta)...)
+			} else {
+				out = append(out, m.text(strings.Repeat(" ", len(vaxis.Characters(labelFor(it)))), style, meta, shape)...)
+			}
+			continue
 		}
-		var buf bytes.Buffer
-		buf.WriteString(labelFor(it))
-		for _, ch := range vaxis.Characters(buf.String()) {
-			out = append(out, modules.EventCell{
-				C:        vaxis.Cell{Character: ch, Style: style},
-				Metadata: strconv.Itoa(i), // index for click routing
-				Mod:      m,
-				// use pointer cursor to hint clickability
-				MouseShape: vaxis.MouseShapeDefault,
-			})
+		if im != nil {
+			out = append(out, im.Cells(m, style, shape, meta)...)
+			continue
 		}
+
+		// nothing to draw, show the name instead
+		out = append(out, m.text(labelFor(it), style, meta, shape)...)
+	}
+	return out
+}
+
+func (m *Module) text(s string, style vaxis.Style, meta string, shape vaxis.MouseShape) []modules.EventCell {
+	var out []modules.EventCell
+	for _, ch := range vaxis.Characters(s) {
+		out = append(out, modules.EventCell{
+			C:          vaxis.Cell{Character: ch, Style: style},
+			Metadata:   meta,
+			Mod:        m,
+			MouseShape: shape,
+		})
 	}
 	return out
 }
+
+func labelFor(it sni.Item) string {
+	if it.Title != "" {
+		return it.Title
+	}
+	if it.Id != "" {
+		return it.Id
+	}
+	return "?"
+}
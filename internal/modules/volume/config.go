@@ -32,6 +32,8 @@ type Options struct {
 	Muted   Mutedoptions                      `yaml:"muted"`
 	Icons   []rune                            `yaml:"icons"`
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
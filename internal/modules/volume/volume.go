@@ -131,6 +131,14 @@ func (mod *VolumeModule) Channels() (<-chan bool, chan<- modules.Event) {
 }
 
 func (mod *VolumeModule) Render() []modules.EventCell {
+	cond := struct {
+		Percent int
+		Muted   bool
+	}{int(mod.Volume), mod.Muted}
+	if mod.opts.Hidden(cond) {
+		return nil
+	}
+
 	style := vaxis.Style{}
 
 	if mod.Muted {
@@ -139,12 +147,12 @@ func (mod *VolumeModule) Render() []modules.EventCell {
 		style.Background = mod.opts.Muted.Bg.Go()
 
 		text := mod.opts.Muted.MuteFormat
-		rch := vaxis.Characters(text)
+		rch := modules.Styled(text, style)
 		r := make([]modules.EventCell, len(rch))
 
 		for i, ch := range rch {
 			r[i] = modules.EventCell{
-				C:          vaxis.Cell{Character: ch, Style: style},
+				C:          ch,
 				Mod:        mod,
 				MouseShape: mod.opts.Cursor.Go(),
 			}
@@ -169,11 +177,11 @@ func (mod *VolumeModule) Render() []modules.EventCell {
 
 		var buf bytes.Buffer
 		_ = mod.opts.Format.Execute(&buf, data)
-		rch := vaxis.Characters(buf.String())
+		rch := modules.Styled(buf.String(), style)
 		r := make([]modules.EventCell, len(rch))
 		for i, ch := range rch {
 			r[i] = modules.EventCell{
-				C:          vaxis.Cell{Character: ch, Style: style},
+				C:          ch,
 				Mod:        mod,
 				MouseShape: mod.opts.Cursor.Go(),
 			}
@@ -33,6 +33,8 @@ type Options struct {
 	NoConnection NoConnectionOptions               `yaml:"noconnection"`
 	Icons        []rune                            `yaml:"icons"`
 	OnClick      config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -231,23 +231,24 @@ func (mod *wifiModule) Render() []modules.EventCell {
 			idx := utils.Clamp((len(mod.opts.Icons)-1)*strength/100, 0, len(mod.opts.Icons)-1)
 			data.Icon = string(mod.opts.Icons[idx])
 		}
-		data.SSID = mod.SSID
+		data.SSID = modules.Escape(mod.SSID)
 		data.Interface = mod.InterfaceName
 	}
 
+	if mod.opts.Hidden(data) {
+		return nil
+	}
+
 	var buf bytes.Buffer
 	if err := format.Execute(&buf, data); err != nil {
 		return nil
 	}
 
-	chars := vaxis.Characters(buf.String())
+	chars := modules.Styled(buf.String(), style)
 	r := make([]modules.EventCell, len(chars))
 	for i, ch := range chars {
 		r[i] = modules.EventCell{
-			C: vaxis.Cell{
-				Character: ch,
-				Style:     style,
-			},
+			C:          ch,
 			Mod:        mod,
 			MouseShape: mod.opts.Cursor.Go(),
 		}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package ws
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/nekorg/pawbar/internal/services/wmexec"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// the command prints the whole state on every change, one line each:
+//
+//	{"workspaces": [{"id": 1, "name": "1", "output": "DP-1", "active": true,
+//	  "focused": true, "urgent": false, "special": false, "ref": "", "windows": [{"class": "kitty", "title": "~"}]}]}
+//
+// and gets {"goto": "<ref or name>"} on stdin when one is clicked. lines
+// without "workspaces" are skipped, so one command can feed title too.
+type execBackend struct {
+	s     *wmexec.Stream
+	lines <-chan []byte
+	mu    sync.RWMutex
+	ws    []Workspace
+	sig   chan struct{}
+}
+
+type execWorkspace struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Output  string `json:"output"`
+	Active  bool   `json:"active"`  // shown on its output
+	Focused bool   `json:"focused"` // has focus, left out it's the first active one
+	Urgent  bool   `json:"urgent"`
+	Special bool   `json:"special"`
+	Ref     string `json:"ref"`
+	Windows []struct {
+		Class string `json:"class"`
+		Title string `json:"title"`
+	} `json:"windows"`
+}
+
+func newExecBackend(s *wmexec.Stream) backend {
+	b := &execBackend{
+		s:     s,
+		lines: s.Subscribe(),
+		sig:   make(chan struct{}, 1),
+	}
+	go b.loop()
+	return b
+}
+
+func (b *execBackend) loop() {
+	for line := range b.lines {
+		var state struct {
+			Workspaces *[]execWorkspace `json:"workspaces"`
+		}
+		if err := json.Unmarshal(line, &state); err != nil {
+			utils.Logger.Println("ws: exec:", err)
+			continue
+		}
+		if state.Workspaces == nil {
+			continue
+		}
+
+		ws := make([]Workspace, 0, len(*state.Workspaces))
+		for _, w := range *state.Workspaces {
+			name := w.Name
+			if name == "" {
+				name = strconv.Itoa(w.Id)
+			}
+			nw := Workspace{
+				ID:      w.Id,
+				Name:    name,
+				Monitor: w.Output,
+				Active:  w.Active,
+				Focused: w.Focused,
+				Urgent:  w.Urgent,
+				Special: w.Special,
+				Ref:     w.Ref,
+			}
+			for _, win := range w.Windows {
+				nw.Windows = append(nw.Windows, Window{Class: win.Class, Title: win.Title})
+			}
+			ws = append(ws, nw)
+		}
+
+		b.mu.Lock()
+		b.ws = ws
+		b.mu.Unlock()
+		b.signal()
+	}
+}
+
+func (b *execBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+func (b *execBackend) List() []Workspace {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Workspace(nil), b.ws...)
+}
+func (b *execBackend) Events() <-chan struct{} { return b.sig }
+func (b *execBackend) Goto(name string) {
+	if err := b.s.Send(map[string]string{"goto": name}); err != nil {
+		utils.Logger.Println("ws: exec:", err)
+	}
+}
@@ -7,18 +7,18 @@
 package ws
 
 import (
-	"sort"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/utils"
 )
 
 type hyprBackend struct {
 	svc *hypr.Service
 	ev  chan hypr.HyprEvent
 	ws  map[int]*Workspace
+	win map[int][]Window
 	mu  sync.RWMutex
 	sig chan struct{}
 }
@@ -32,8 +32,12 @@ func newHyprBackend(s *hypr.Service) backend {
 	}
 
 	b.refreshWorkspaceCache()
+	b.refreshWindows()
 
-	for _, e := range []string{"workspacev2", "focusedmonv2", "createworkspacev2", "destroyworkspacev2", "activespecial", "renameworkspace", "urgent"} {
+	for _, e := range []string{"workspacev2", "focusedmonv2", "createworkspacev2", "destroyworkspacev2", "activespecial", "renameworkspace", "moveworkspacev2", "urgent"} {
+		b.svc.RegisterChannel(e, b.ev)
+	}
+	for _, e := range []string{"openwindow", "closewindow", "movewindowv2"} {
 		b.svc.RegisterChannel(e, b.ev)
 	}
 
@@ -43,31 +47,100 @@ func newHyprBackend(s *hypr.Service) backend {
 
 func (b *hyprBackend) loop() {
 	for e := range b.ev {
-		if !b.validate(e) {
+		ev, err := e.Parse()
+		if err != nil {
+			utils.Logger.Println("ws:", err)
+			continue
+		}
+
+		switch e := ev.(type) {
+		case hypr.ResyncEvent:
+			b.refreshWorkspaceCache()
+			b.refreshWindows()
+			b.signal()
+			continue
+		case hypr.OpenWindowEvent, hypr.CloseWindowEvent, hypr.MoveWindowV2Event:
+			b.refreshWindows()
+			b.signal()
+			continue
+		case hypr.CreateWorkspaceV2Event:
+			b.createWorkspace(e.Id, e.Name)
+			b.signal()
+			continue
+		case hypr.MoveWorkspaceV2Event:
+			// both monitors may show another workspace now
+			b.refreshWorkspaceCache()
+			b.signal()
+			continue
+		}
+
+		if !b.validate(ev) {
 			b.refreshWorkspaceCache()
+			b.signal()
+			continue
 		}
 
-		if b.handleEvent(e) {
+		if b.handleEvent(ev) {
 			b.signal()
 		}
 	}
 }
 
 func (b *hyprBackend) refreshWorkspaceCache() {
+	workspaces, err := hypr.Workspaces()
+	if err != nil {
+		utils.Logger.Println("ws:", err)
+		return
+	}
+	active, _ := hypr.ActiveWorkspace()
+
+	// every monitor shows a workspace, and maybe a special one over it
+	shown := make(map[int]bool)
+	if mons, err := hypr.Monitors(); err == nil {
+		for _, m := range mons {
+			shown[m.ActiveWorkspace.Id] = true
+			if m.SpecialWorkspace.Id != 0 {
+				shown[m.SpecialWorkspace.Id] = true
+			}
+		}
+	} else {
+		utils.Logger.Println("ws:", err)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.ws = make(map[int]*Workspace)
-
-	workspaces := hypr.GetWorkspaces()
-
 	for _, w := range workspaces {
+		special := strings.HasPrefix(w.Name, "special:")
 		b.ws[w.Id] = &Workspace{
 			ID:      w.Id,
 			Name:    w.Name,
-			Active:  w.Id == hypr.GetActiveWorkspace().Id,
-			Special: strings.HasPrefix(w.Name, "special:"),
+			Monitor: w.Monitor,
+			Active:  shown[w.Id] || w.Id == active.Id,
+			Focused: w.Id == active.Id,
+			Special: special,
+		}
+	}
+}
+
+// windows per workspace id, from the client list
+func (b *hyprBackend) refreshWindows() {
+	clients, err := hypr.Clients()
+	if err != nil {
+		utils.Logger.Println("ws:", err)
+		return
+	}
+
+	win := make(map[int][]Window)
+	for _, c := range clients {
+		if c.Mapped {
+			win[c.Workspace.Id] = append(win[c.Workspace.Id], Window{Class: c.Class, Title: c.Title})
 		}
 	}
+
+	b.mu.Lock()
+	b.win = win
+	b.mu.Unlock()
 }
 
 func (b *hyprBackend) signal() {
@@ -77,61 +150,42 @@ func (b *hyprBackend) signal() {
 	}
 }
 
-func (b *hyprBackend) validate(e hypr.HyprEvent) bool {
+// validate reports whether the cache is in a state ev can be applied to
+func (b *hyprBackend) validate(ev any) bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	switch e.Event {
-	case "workspacev2":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		_, ok := b.ws[id]
-		return ok
-
-	case "focusedmonv2":
-		id, _ := strconv.Atoi(e.Data[strings.LastIndex(e.Data, ",")+1:])
-		_, ok := b.ws[id]
-		return ok
-
-	case "createworkspacev2":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		_, ok := b.ws[id]
-		return !ok
-
-	case "destroyworkspacev2":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		_, ok := b.ws[id]
-		return ok
 
-	case "renameworkspace":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		_, ok := b.ws[id]
-		return ok
+	known := func(id int) bool { _, ok := b.ws[id]; return ok }
+	switch e := ev.(type) {
+	case hypr.WorkspaceV2Event:
+		return known(e.Id)
+	case hypr.FocusedMonV2Event:
+		return known(e.WorkspaceId)
+	case hypr.DestroyWorkspaceV2Event:
+		return known(e.Id)
+	case hypr.RenameWorkspaceEvent:
+		return known(e.Id)
 	}
 
 	return true
 }
 
-func (b *hyprBackend) handleEvent(e hypr.HyprEvent) bool {
+func (b *hyprBackend) handleEvent(ev any) bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	switch e.Event {
-	case "workspacev2":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		b.setActiveWorkspace(id)
-	case "createworkspacev2":
-		id_str, name, _ := strings.Cut(e.Data, ",")
-		id, _ := strconv.Atoi(id_str)
-		b.createWorkspace(id, name)
-	case "destroyworkspacev2":
-		id, _ := strconv.Atoi(e.Data[:strings.IndexRune(e.Data, ',')])
-		b.destroyWorkspace(id)
-	case "activespecial":
-		b.activateSpecialWorkspace(e.Data[:strings.IndexRune(e.Data, ',')])
-	case "urgent":
-		b.setWorkspaceUrgent(e.Data)
-	case "renameworkspace":
-		idr, name, _ := strings.Cut(e.Data, ",")
-		id, _ := strconv.Atoi(idr)
-		b.renameWorkspace(id, name)
+	switch e := ev.(type) {
+	case hypr.WorkspaceV2Event:
+		b.setActiveWorkspace(e.Id)
+	case hypr.FocusedMonV2Event:
+		b.setActiveWorkspace(e.WorkspaceId)
+	case hypr.DestroyWorkspaceV2Event:
+		b.destroyWorkspace(e.Id)
+	case hypr.ActiveSpecialEvent:
+		b.activateSpecialWorkspace(e.Name, e.Monitor)
+	case hypr.UrgentEvent:
+		b.setWorkspaceUrgent(e.Address)
+	case hypr.RenameWorkspaceEvent:
+		b.renameWorkspace(e.Id, e.NewName)
 	default:
 		return false
 	}
@@ -146,23 +200,48 @@ func (b *hyprBackend) renameWorkspace(id int, name string) {
 	}
 }
 
+// id is shown on its monitor, replacing the one there, and has focus
 func (b *hyprBackend) setActiveWorkspace(id int) {
+	mon := b.ws[id].Monitor
 	for _, w := range b.ws {
-		if !w.Special {
+		if w.Special {
+			continue
+		}
+		if w.Monitor == mon {
 			w.Active = false
 		}
+		w.Focused = false
 	}
 
 	b.ws[id].Active = true
+	b.ws[id].Focused = true
 	b.ws[id].Urgent = false
 }
 
+// the event doesn't say where the workspace was created, ask for it
 func (b *hyprBackend) createWorkspace(id int, name string) {
-	b.ws[id] = &Workspace{
-		ID:      id,
-		Name:    name,
-		Active:  false,
-		Special: strings.HasPrefix(name, "special:"),
+	workspaces, err := hypr.Workspaces()
+	if err != nil {
+		utils.Logger.Println("ws:", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, w := range workspaces {
+		if w.Id != id {
+			continue
+		}
+		if ws, ok := b.ws[id]; ok {
+			ws.Monitor = w.Monitor
+			return
+		}
+		b.ws[id] = &Workspace{
+			ID:      id,
+			Name:    name,
+			Monitor: w.Monitor,
+			Special: strings.HasPrefix(name, "special:"),
+		}
 	}
 }
 
@@ -170,30 +249,41 @@ func (b *hyprBackend) destroyWorkspace(id int) {
 	delete(b.ws, id)
 }
 
-func (b *hyprBackend) activateSpecialWorkspace(name string) {
-	active := name != ""
-
+// a monitor shows at most one special workspace, opening one there closes
+// the other and opening it on another monitor moves it there
+func (b *hyprBackend) activateSpecialWorkspace(name, monitor string) {
 	for _, w := range b.ws {
-		if w.Special {
-			w.Active = active
+		switch {
+		case !w.Special:
+		case w.Name == name:
+			w.Active = true
+			w.Monitor = monitor
+		case w.Monitor == monitor:
+			w.Active = false
 		}
 	}
 }
 
 func (b *hyprBackend) setWorkspaceUrgent(address string) {
-	clients := hypr.GetClients()
+	clients, err := hypr.Clients()
+	if err != nil {
+		utils.Logger.Println("ws:", err)
+		return
+	}
 
 	activeId := 0
 	for _, w := range b.ws {
-		if w.Active && !w.Special {
+		if w.Focused && !w.Special {
 			activeId = w.ID
 		}
 	}
 
 	for _, client := range clients {
-		client_address, _ := strings.CutPrefix(client.Address, "0x")
-		if client_address == address && client.Workspace.Id != activeId {
-			b.ws[client.Workspace.Id].Urgent = true
+		if client.Address != address || client.Workspace.Id == activeId {
+			continue
+		}
+		if w, ok := b.ws[client.Workspace.Id]; ok {
+			w.Urgent = true
 		}
 	}
 }
@@ -203,10 +293,21 @@ func (b *hyprBackend) List() []Workspace {
 	defer b.mu.RUnlock()
 	ws := make([]Workspace, 0, len(b.ws))
 	for _, v := range b.ws {
-		ws = append(ws, Workspace{v.ID, v.Name, v.Active, v.Urgent, v.Special})
+		w := *v
+		w.Windows = b.win[w.ID]
+		ws = append(ws, w)
 	}
-	sort.Slice(ws, func(a, b int) bool { return ws[a].ID < ws[b].ID })
 	return ws
 }
 func (b *hyprBackend) Events() <-chan struct{} { return b.sig }
-func (b *hyprBackend) Goto(name string)        { hypr.GoToWorkspace(name) }
+func (b *hyprBackend) Goto(name string) {
+	var err error
+	if special, ok := strings.CutPrefix(name, "special:"); ok {
+		err = hypr.Dispatch("togglespecialworkspace", special)
+	} else {
+		err = hypr.Dispatch("workspace", name)
+	}
+	if err != nil {
+		utils.Logger.Println("ws:", err)
+	}
+}
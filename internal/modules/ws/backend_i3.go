@@ -7,7 +7,6 @@
 package ws
 
 import (
-	"sort"
 	"sync"
 
 	"github.com/nekorg/pawbar/internal/services/i3"
@@ -17,7 +16,7 @@ import (
 type i3Backend struct {
 	svc *i3.Service
 	ev  chan interface{}
-	ws  map[int]*Workspace
+	ws  map[string]*Workspace // by name, named workspaces share num -1
 	mu  sync.RWMutex
 	sig chan struct{}
 }
@@ -26,13 +25,14 @@ func newI3Backend(s *i3.Service) backend {
 	b := &i3Backend{
 		svc: s,
 		ev:  make(chan interface{}),
-		ws:  make(map[int]*Workspace),
+		ws:  make(map[string]*Workspace),
 		sig: make(chan struct{}, 1),
 	}
 
 	b.refreshWorkspaceCache()
 
-	b.svc.RegisterChannel("workspaces", b.ev)
+	b.svc.RegisterChannel(i3.EventWorkspace, b.ev)
+	b.svc.RegisterChannel(i3.EventWindow, b.ev)
 
 	go b.loop()
 	return b
@@ -40,34 +40,90 @@ func newI3Backend(s *i3.Service) backend {
 
 func (b *i3Backend) loop() {
 	for e := range b.ev {
-		if evt, ok := e.(i3.I3Event); ok {
-			utils.Logger.Println("DEBUG: ws: i3: Event type:", evt)
+		switch evt := e.(type) {
+		case i3.I3Event, i3.ResyncEvent:
 			b.refreshWorkspaceCache()
 			b.signal()
-		} else {
+		case i3.I3WEvent:
+			// only these change which windows a workspace has, showing
+			// a scratchpad window only tells focus
+			switch evt.Change {
+			case "new", "close", "move", "floating":
+			case "focus":
+				if !b.hasScratchpad() {
+					continue
+				}
+			default:
+				continue
+			}
+			b.refreshWorkspaceCache()
+			b.signal()
+		case i3.ErrorEvent:
+			// keep showing what we had, a resync follows the reconnect
+			utils.Logger.Println("ws: i3:", evt.Err)
+		default:
 			utils.Logger.Println("DEBUG: ws: i3: Unknown event type", e)
 		}
 	}
 }
 
 func (b *i3Backend) refreshWorkspaceCache() {
+	workspaces, err := i3.GetWorkspaces()
+	if err != nil {
+		utils.Logger.Println("ws: i3:", err)
+		return
+	}
+
+	windows, err := i3.WorkspaceWindows()
+	if err != nil {
+		utils.Logger.Println("ws: i3:", err)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.ws = make(map[int]*Workspace)
-
-	workspaces := i3.GetWorkspaces()
-	active := i3.GetActiveWorkspace()
+	b.ws = make(map[string]*Workspace)
 
 	for _, w := range workspaces {
-		b.ws[w.Id] = &Workspace{
-			ID:     w.Id,
-			Name:   w.Name,
-			Active: w.Id == active.Id,
-			Urgent: w.Urgent,
+		ws := &Workspace{
+			ID:      w.Id,
+			Name:    w.Name,
+			Monitor: w.Output,
+			Active:  w.Visible,
+			Focused: w.Focused,
+			Urgent:  w.Urgent,
+		}
+		for _, win := range windows[w.Name] {
+			ws.Windows = append(ws.Windows, Window{Class: win.Class, Title: win.Title})
 		}
+		b.ws[w.Name] = ws
+	}
+
+	// the scratchpad shows up as a special workspace holding all its
+	// windows, active while one of them is shown
+	scratch := &Workspace{ID: -1, Name: "scratchpad", Special: true}
+	for name, wins := range windows {
+		for _, win := range wins {
+			if !win.Scratchpad {
+				continue
+			}
+			scratch.Windows = append(scratch.Windows, Window{Class: win.Class, Title: win.Title})
+			if name != i3.ScratchpadWorkspace {
+				scratch.Active = true
+			}
+		}
+	}
+	if len(scratch.Windows) > 0 {
+		b.ws[i3.ScratchpadWorkspace] = scratch
 	}
 }
 
+func (b *i3Backend) hasScratchpad() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.ws[i3.ScratchpadWorkspace]
+	return ok
+}
+
 func (b *i3Backend) signal() {
 	select {
 	case b.sig <- struct{}{}:
@@ -81,10 +137,19 @@ func (b *i3Backend) List() []Workspace {
 
 	ws := make([]Workspace, 0, len(b.ws))
 	for _, v := range b.ws {
-		ws = append(ws, Workspace{v.ID, v.Name, v.Active, v.Urgent, v.Special})
+		ws = append(ws, *v)
 	}
-	sort.Slice(ws, func(a, b int) bool { return ws[a].ID < ws[b].ID })
 	return ws
 }
 func (b *i3Backend) Events() <-chan struct{} { return b.sig }
-func (b *i3Backend) Goto(name string)        { i3.GoToWorkspace(name) }
+func (b *i3Backend) Goto(name string) {
+	var err error
+	if name == i3.ScratchpadWorkspace {
+		err = i3.RunCommand("scratchpad show")
+	} else {
+		err = i3.GoToWorkspace(name)
+	}
+	if err != nil {
+		utils.Logger.Println("ws: i3:", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package ws
+
+import (
+	"strconv"
+
+	"github.com/nekorg/pawbar/internal/services/niri"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// the niri service keeps the state, this only turns it into workspaces
+type niriBackend struct {
+	svc *niri.Service
+	ev  chan interface{}
+	sig chan struct{}
+}
+
+func newNiriBackend(s *niri.Service) backend {
+	b := &niriBackend{
+		svc: s,
+		ev:  make(chan interface{}),
+		sig: make(chan struct{}, 1),
+	}
+
+	for _, e := range []string{
+		niri.EventWorkspacesChanged, niri.EventWorkspaceActivated, niri.EventWorkspaceUrgencyChanged,
+		niri.EventWindowsChanged, niri.EventWindowOpenedOrChanged, niri.EventWindowClosed,
+	} {
+		b.svc.RegisterChannel(e, b.ev)
+	}
+
+	go b.loop()
+	return b
+}
+
+func (b *niriBackend) loop() {
+	for e := range b.ev {
+		switch evt := e.(type) {
+		case niri.Event, niri.ResyncEvent:
+			b.signal()
+		case niri.ErrorEvent:
+			utils.Logger.Println("ws: niri:", evt.Err)
+		}
+	}
+}
+
+func (b *niriBackend) signal() {
+	select {
+	case b.sig <- struct{}{}:
+	default:
+	}
+}
+
+// workspaces are per output in niri, ID is the index on the output and Ref
+// the real id
+func (b *niriBackend) List() []Workspace {
+	windows := make(map[uint64][]Window)
+	for _, w := range b.svc.Windows() {
+		windows[w.WorkspaceId] = append(windows[w.WorkspaceId], Window{Class: w.AppId, Title: w.Title})
+	}
+
+	var ws []Workspace
+	for _, w := range b.svc.Workspaces() {
+		name := w.Name
+		if name == "" {
+			name = strconv.Itoa(w.Idx)
+		}
+		ws = append(ws, Workspace{
+			ID:      w.Idx,
+			Name:    name,
+			Monitor: w.Output,
+			Active:  w.IsActive,
+			Focused: w.IsFocused,
+			Urgent:  w.IsUrgent,
+			Windows: windows[w.Id],
+			Ref:     "id:" + strconv.FormatUint(w.Id, 10),
+		})
+	}
+	return ws
+}
+
+func (b *niriBackend) Events() <-chan struct{} { return b.sig }
+func (b *niriBackend) Goto(name string) {
+	if err := niri.FocusWorkspace(niri.ParseRef(name)); err != nil {
+		utils.Logger.Println("ws: niri:", err)
+	}
+}
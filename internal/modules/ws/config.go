@@ -31,15 +31,44 @@ type SpecialOptions struct {
 	Bg config.Color `yaml:"bg"`
 }
 
+// icons for the windows on a workspace
+type AppOptions struct {
+	Show bool `yaml:"show"`
+	// window class to icon, lowercase keys match any case
+	Icons map[string]config.Icon `yaml:"icons"`
+	// for classes not in icons, those are left out when empty
+	Fallback config.Icon `yaml:"fallback"`
+	Unique   bool        `yaml:"unique"`
+}
+
 type Options struct {
-	Fg      config.Color                      `yaml:"fg"`
-	Bg      config.Color                      `yaml:"bg"`
-	Cursor  config.Cursor                     `yaml:"cursor"`
-	Format  config.Format                     `yaml:"format"`
-	Special SpecialOptions                    `yaml:"special"`
-	Active  ActiveOptions                     `yaml:"active"`
-	Urgent  UrgentOptions                     `yaml:"urgent"`
+	Fg      config.Color   `yaml:"fg"`
+	Bg      config.Color   `yaml:"bg"`
+	Cursor  config.Cursor  `yaml:"cursor"`
+	Format  config.Format  `yaml:"format"`
+	Special SpecialOptions `yaml:"special"`
+	Active  ActiveOptions  `yaml:"active"`
+	Urgent  UrgentOptions  `yaml:"urgent"`
+
+	Output         config.OutputMode `yaml:"output"`
+	GroupSeparator string            `yaml:"group_separator"`
+	// shown even when they don't exist, like ["1-10"]
+	Persistent config.Names     `yaml:"persistent"`
+	Sort       config.SortOrder `yaml:"sort"`
+	// the wheel goes to the next/previous shown workspace
+	Cycle bool `yaml:"cycle"`
+
+	// workspace name or id to icon, replaces the name in WSID
+	Icons map[string]config.Icon `yaml:"icons"`
+	Apps  AppOptions             `yaml:"apps"`
+
+	// auto picks by the environment, exec runs the command in exec
+	Backend config.Backend `yaml:"backend"`
+	Exec    []string       `yaml:"exec"`
+
 	OnClick config.MouseActions[MouseOptions] `yaml:"onmouse"`
+
+	config.Visibility `yaml:",inline"`
 }
 
 type MouseOptions struct {
@@ -57,7 +86,8 @@ func defaultOptions() Options {
 	urgClr, _ := colors.ParseColor("@urgent")
 
 	return Options{
-		Format: config.Format{Template: fw},
+		Format:         config.Format{Template: fw},
+		GroupSeparator: "│",
 		Special: SpecialOptions{
 			Fg: config.Color(actClr),
 			Bg: config.Color(spclClr),
@@ -9,22 +9,34 @@ package ws
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"sort"
 	"strconv"
+	"strings"
 
 	"git.sr.ht/~rockorager/vaxis"
 	"github.com/nekorg/pawbar/internal/config"
 	"github.com/nekorg/pawbar/internal/modules"
 	"github.com/nekorg/pawbar/internal/services/hypr"
 	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/services/niri"
+	"github.com/nekorg/pawbar/internal/services/wmexec"
 )
 
+type Window struct {
+	Class string
+	Title string
+}
+
 type Workspace struct {
 	ID      int
 	Name    string
-	Active  bool
+	Monitor string // empty for persistent ones that don't exist yet
+	Active  bool   // shown on its output
+	Focused bool   // the one with focus
 	Urgent  bool
 	Special bool
+	Windows []Window
+	Ref     string // what Goto takes, when the name or id doesn't do
 }
 
 type Format int
@@ -81,12 +93,19 @@ func (mod *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 					}
 					btn := config.ButtonName(ev)
 
-					if btn == "left" {
-						go mod.b.Goto(e.Cell.Metadata)
-					}
-					if btn == "right" {
+					switch btn {
+					case "left":
+						// group separators have no workspace
+						if e.Cell.Metadata != "" {
+							go mod.b.Goto(e.Cell.Metadata)
+						}
+					case "right":
 						mod.format.toggle()
 						mod.receive <- true
+					case "wheel-up", "wheel-down":
+						if _, ok := mod.opts.OnClick.Actions[btn]; mod.opts.Cycle && !ok {
+							go mod.cycle(btn == "wheel-down")
+						}
 					}
 
 					if mod.opts.OnClick.Dispatch(btn, &mod.initialOpts, &mod.opts) {
@@ -113,68 +132,246 @@ func (mod *Module) Run() (<-chan bool, chan<- modules.Event, error) {
 }
 
 func (mod *Module) selectBackend() error {
-	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+	backend := mod.opts.Backend.Resolve(len(mod.opts.Exec) > 0)
+	switch backend {
+	case config.BackendHypr:
 		svc, ok := hypr.Register()
 		if !ok {
 			return fmt.Errorf("Could not start hypr service.")
 		}
 		mod.b = newHyprBackend(svc)
-		mod.bname = "hypr"
-	} else if os.Getenv("I3SOCK") != "" || os.Getenv("SWAYSOCK") != "" {
+	case config.BackendI3:
 		svc, ok := i3.Register()
 		if !ok {
 			return fmt.Errorf("Could not start i3 service.")
 		}
 		mod.b = newI3Backend(svc)
-		mod.bname = "i3"
-	} else {
+	case config.BackendNiri:
+		svc, ok := niri.Register()
+		if !ok {
+			return fmt.Errorf("Could not start niri service.")
+		}
+		mod.b = newNiriBackend(svc)
+	case config.BackendExec:
+		if len(mod.opts.Exec) == 0 {
+			return fmt.Errorf("The exec backend needs a command in exec.")
+		}
+		s, err := wmexec.Register(mod.opts.Exec)
+		if err != nil {
+			return fmt.Errorf("Could not start exec backend: %w", err)
+		}
+		mod.b = newExecBackend(s)
+	default:
 		return fmt.Errorf("Could not find a wm backend for current environment.")
 	}
+	mod.bname = string(backend)
 
 	return nil
 }
 
+// workspaces returns what the options ask for: persistent ones filled in,
+// filtered by output and sorted
+func (mod *Module) workspaces() []Workspace {
+	list := mod.b.List()
+
+	have := make(map[string]bool, len(list))
+	for _, w := range list {
+		have[w.Name] = true
+	}
+	for _, name := range mod.opts.Persistent {
+		if !have[name] {
+			id, _ := strconv.Atoi(name)
+			list = append(list, Workspace{ID: id, Name: name})
+		}
+	}
+
+	if out := modules.PanelMonitor().Name; mod.opts.Output == config.OutputCurrent && out != "" {
+		filtered := list[:0]
+		for _, w := range list {
+			if w.Monitor == out || w.Monitor == "" {
+				filtered = append(filtered, w)
+			}
+		}
+		list = filtered
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if mod.opts.Output == config.OutputGroup && a.Monitor != b.Monitor {
+			// placeholders go last
+			if a.Monitor == "" || b.Monitor == "" {
+				return b.Monitor == ""
+			}
+			return a.Monitor < b.Monitor
+		}
+		if mod.opts.Sort == config.SortName && a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Name < b.Name
+	})
+	return list
+}
+
+// configured icon for w, by name and then by id
+func (mod *Module) icon(w Workspace) (string, bool) {
+	if icon, ok := mod.opts.Icons[w.Name]; ok {
+		return icon.Go(), true
+	}
+	if icon, ok := mod.opts.Icons[strconv.Itoa(w.ID)]; ok {
+		return icon.Go(), true
+	}
+	return "", false
+}
+
+// appIcons returns the glyphs of the windows on w, space separated
+func (mod *Module) appIcons(w Workspace) string {
+	apps := mod.opts.Apps
+	seen := make(map[string]bool)
+
+	var icons []string
+	for _, win := range w.Windows {
+		icon, ok := apps.Icons[win.Class]
+		if !ok {
+			icon, ok = apps.Icons[strings.ToLower(win.Class)]
+		}
+		if !ok {
+			icon = apps.Fallback
+		}
+		if icon == "" || (apps.Unique && seen[icon.Go()]) {
+			continue
+		}
+		seen[icon.Go()] = true
+		icons = append(icons, icon.Go())
+	}
+	return strings.Join(icons, " ")
+}
+
+// what Goto gets for w
+func (mod *Module) target(w Workspace) string {
+	switch {
+	case w.Ref != "":
+		return w.Ref
+	case mod.bname == "i3" && w.Special:
+		return i3.ScratchpadWorkspace
+	case mod.bname != "hypr", w.Special:
+		return w.Name
+	case w.ID > 0:
+		return strconv.Itoa(w.ID)
+	default:
+		// named (and not yet created) workspaces have no usable id
+		return "name:" + w.Name
+	}
+}
+
+// label is what WSID shows for w when there's no icon for it
+func (mod *Module) label(w Workspace) string {
+	if !w.Special {
+		return w.Name
+	}
+	if mod.bname == "i3" {
+		return "S"
+	}
+	if name := strings.TrimPrefix(w.Name, "special:"); name != "" {
+		return name
+	}
+	return "S"
+}
+
+// current returns the index of the focused workspace in list, or of the
+// first active one when the focused one isn't in it (another output's)
+func current(list []Workspace) int {
+	active := -1
+	for i, w := range list {
+		switch {
+		case w.Special:
+		case w.Focused:
+			return i
+		case w.Active && active < 0:
+			active = i
+		}
+	}
+	return active
+}
+
+// cycle goes to the next (or previous) workspace the module shows
+func (mod *Module) cycle(next bool) {
+	var list []Workspace
+	for _, w := range mod.workspaces() {
+		if !w.Special {
+			list = append(list, w)
+		}
+	}
+	if len(list) == 0 {
+		return
+	}
+	cur := current(list)
+
+	i := len(list) - 1
+	switch {
+	case next:
+		i = (cur + 1) % len(list)
+	case cur > 0:
+		i = cur - 1
+	}
+	mod.b.Goto(mod.target(list[i]))
+}
+
 func (mod *Module) Render() []modules.EventCell {
-	data := struct{ WSID string }{}
+	// WSID is the label padded (and followed by the app icons when shown),
+	// the rest is for formats that lay things out themselves
+	type wsData struct {
+		WSID    string
+		Name    string
+		Windows []Window
+		Icons   string
+		Count   int
+	}
 	format := mod.opts.Format
 
 	var toRender []Workspace
 	switch mod.format {
-	case FormatAll:
-		toRender = mod.b.List()
-
 	case FormatCurr:
-		for _, w := range mod.b.List() {
-			if w.Active {
-				toRender = []Workspace{w}
-				break
-			}
+		list := mod.workspaces()
+		if i := current(list); i >= 0 {
+			toRender = []Workspace{list[i]}
 		}
 
 	default:
-		toRender = mod.b.List()
+		toRender = mod.workspaces()
 	}
 
 	var cells []modules.EventCell
-	for _, w := range toRender {
-		wsName := w.Name
-		if w.Special {
-			wsName = "S"
+	for i, w := range toRender {
+		// evaluated per workspace, so hide_when can drop single ones
+		if mod.opts.Hidden(w) {
+			continue
 		}
-		var meta string
-		if mod.bname == "hypr" {
-			meta = strconv.Itoa(w.ID)
-		} else {
-			meta = wsName
+		if mod.opts.Output == config.OutputGroup && mod.opts.GroupSeparator != "" &&
+			i > 0 && w.Monitor != toRender[i-1].Monitor {
+			sep := vaxis.Style{
+				Foreground: mod.opts.Fg.Go(),
+				Background: mod.opts.Bg.Go(),
+			}
+			for _, ch := range modules.Styled(mod.opts.GroupSeparator, sep) {
+				cells = append(cells, modules.EventCell{C: ch, Mod: mod})
+			}
 		}
+		wsName := modules.Escape(mod.label(w))
+		meta := mod.target(w)
 		style := vaxis.Style{
 			Foreground: mod.opts.Fg.Go(),
 			Background: mod.opts.Bg.Go(),
 		}
 		switch {
 		case w.Special:
-			style.Foreground = mod.opts.Special.Fg.Go()
-			style.Background = mod.opts.Special.Bg.Go()
+			// only while it's open
+			if w.Active {
+				style.Foreground = mod.opts.Special.Fg.Go()
+				style.Background = mod.opts.Special.Bg.Go()
+			}
 		case w.Active:
 			style.Foreground = mod.opts.Active.Fg.Go()
 			style.Background = mod.opts.Active.Bg.Go()
@@ -182,19 +379,37 @@ func (mod *Module) Render() []modules.EventCell {
 			style.Foreground = mod.opts.Urgent.Fg.Go()
 			style.Background = mod.opts.Urgent.Bg.Go()
 		}
+		data := wsData{
+			Name:  modules.Escape(w.Name),
+			Icons: mod.appIcons(w),
+			Count: len(w.Windows),
+		}
+		for _, win := range w.Windows {
+			data.Windows = append(data.Windows, Window{
+				Class: modules.Escape(win.Class),
+				Title: modules.Escape(win.Title),
+			})
+		}
+		if icon, ok := mod.icon(w); ok {
+			wsName = icon
+		}
+		// the scratchpad has no name worth showing, its window count is
+		if w.Special && mod.bname == "i3" {
+			wsName += " " + strconv.Itoa(len(w.Windows))
+		}
 		data.WSID = " " + wsName + " "
+		if mod.opts.Apps.Show && data.Icons != "" {
+			data.WSID += data.Icons + " "
+		}
 		var buf bytes.Buffer
 		if err := format.Execute(&buf, data); err != nil {
 			continue
 		}
 
 		// split into cells
-		for _, ch := range vaxis.Characters(buf.String()) {
+		for _, ch := range modules.Styled(buf.String(), style) {
 			cells = append(cells, modules.EventCell{
-				C: vaxis.Cell{
-					Character: ch,
-					Style:     style,
-				},
+				C:          ch,
 				Metadata:   meta,
 				Mod:        mod,
 				MouseShape: vaxis.MouseShapeClickable,
@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+// Package output asks the compositor which outputs there are and which one
+// the bar is on, without cgo.
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nekorg/pawbar/internal/services/hypr"
+	"github.com/nekorg/pawbar/internal/services/i3"
+	"github.com/nekorg/pawbar/internal/services/niri"
+)
+
+type Monitor struct {
+	Name                       string
+	X, Y                       int // position in the layout, logical pixels
+	Width, Height, RefreshRate int // current mode
+	Scale                      Scale
+}
+
+type Scale struct {
+	X, Y float64
+}
+
+// Logical converts physical pixels on m to logical (layout) pixels.
+func (m Monitor) Logical(px int) int {
+	if m.Scale.X <= 0 {
+		return px
+	}
+	return int(float64(px) / m.Scale.X)
+}
+
+// Current returns the monitor our panel is on. hyprland can tell which output
+// the panel's layer surface is on, elsewhere the focused output is used since
+// that is where an unpinned panel opens.
+func Current() (Monitor, error) {
+	mons, err := outputs()
+	if err != nil {
+		return Monitor{}, err
+	}
+
+	name := ""
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		name = hyprPanelOutput()
+	}
+	for _, m := range mons {
+		if m.Name == name {
+			return m.Monitor, nil
+		}
+	}
+	for _, m := range mons {
+		if m.focused {
+			return m.Monitor, nil
+		}
+	}
+	if len(mons) > 0 {
+		return mons[0].Monitor, nil
+	}
+	return Monitor{}, fmt.Errorf("no outputs")
+}
+
+// Lookup returns the current state of the named output.
+func Lookup(name string) (Monitor, error) {
+	mons, err := outputs()
+	if err != nil {
+		return Monitor{}, err
+	}
+	for _, m := range mons {
+		if m.Name == name {
+			return m.Monitor, nil
+		}
+	}
+	return Monitor{}, fmt.Errorf("no output named %q", name)
+}
+
+type entry struct {
+	Monitor
+	focused bool
+}
+
+func outputs() ([]entry, error) {
+	switch {
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		mons, err := hypr.Monitors()
+		if err != nil {
+			return nil, err
+		}
+		out := make([]entry, 0, len(mons))
+		for _, m := range mons {
+			out = append(out, entry{
+				Monitor: Monitor{
+					Name:        m.Name,
+					X:           m.X,
+					Y:           m.Y,
+					Width:       m.Width,
+					Height:      m.Height,
+					RefreshRate: int(m.RefreshRate + 0.5),
+					Scale:       Scale{m.Scale, m.Scale},
+				},
+				focused: m.Focused,
+			})
+		}
+		return out, nil
+
+	case os.Getenv("I3SOCK") != "" || os.Getenv("SWAYSOCK") != "":
+		outs, err := i3.GetOutputs()
+		if err != nil {
+			return nil, err
+		}
+		out := make([]entry, 0, len(outs))
+		for _, o := range outs {
+			if !o.Active {
+				continue
+			}
+			scale := o.Scale
+			if scale <= 0 {
+				scale = 1 // i3 doesn't scale outputs
+			}
+			w, h := o.CurrentMode.Width, o.CurrentMode.Height
+			if w == 0 {
+				w, h = o.Rect.Width, o.Rect.Height
+			}
+			out = append(out, entry{
+				Monitor: Monitor{
+					Name:        o.Name,
+					X:           o.Rect.X,
+					Y:           o.Rect.Y,
+					Width:       w,
+					Height:      h,
+					RefreshRate: (o.CurrentMode.Refresh + 500) / 1000,
+					Scale:       Scale{scale, scale},
+				},
+				focused: o.Focused,
+			})
+		}
+		return out, nil
+
+	case os.Getenv("NIRI_SOCKET") != "":
+		outs, err := niri.Outputs()
+		if err != nil {
+			return nil, err
+		}
+		focused := ""
+		if f, err := niri.FocusedOutput(); err == nil && f != nil {
+			focused = f.Name
+		}
+		out := make([]entry, 0, len(outs))
+		for _, o := range outs {
+			if o.Logical == nil {
+				continue
+			}
+			m := Monitor{
+				Name:  o.Name,
+				X:     o.Logical.X,
+				Y:     o.Logical.Y,
+				Scale: Scale{o.Logical.Scale, o.Logical.Scale},
+			}
+			if o.CurrentMode != nil && *o.CurrentMode < len(o.Modes) {
+				mode := o.Modes[*o.CurrentMode]
+				m.Width, m.Height = mode.Width, mode.Height
+				m.RefreshRate = (mode.RefreshRate + 500) / 1000
+			}
+			out = append(out, entry{Monitor: m, focused: o.Name == focused})
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("no compositor ipc available")
+}
+
+// kitty owns the panel's layer surface and we are its child
+func hyprPanelOutput() string {
+	layers, err := hypr.Layers()
+	if err != nil {
+		return ""
+	}
+	ppid := os.Getppid()
+	for mon, ls := range layers {
+		for _, l := range ls {
+			if l.Pid == ppid {
+				return mon
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package hypr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// socket1 client, one connection per request like hyprctl
+
+// Request sends a raw request (like "dispatch workspace 1" or "j/clients")
+// and returns the reply.
+func Request(cmd string) ([]byte, error) {
+	sockaddr1, _ := GetHyprSocketAddrs()
+	sock, err := net.Dial("unix", sockaddr1)
+	if err != nil {
+		return nil, err
+	}
+	defer sock.Close()
+
+	if _, err := sock.Write([]byte(cmd)); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(sock)
+}
+
+// Query runs a json query (like "monitors") and decodes the reply into v.
+func Query(cmd string, v any) error {
+	reply, err := Request("j/" + cmd)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(reply, v); err != nil {
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+	return nil
+}
+
+// Dispatch runs a dispatcher, like Dispatch("workspace", "3").
+func Dispatch(dispatcher string, args ...string) error {
+	cmd := strings.TrimSpace("dispatch " + dispatcher + " " + strings.Join(args, " "))
+	reply, err := Request(cmd)
+	if err != nil {
+		return err
+	}
+	if r := strings.TrimSpace(string(reply)); r != "ok" {
+		return fmt.Errorf("%s: %s", cmd, r)
+	}
+	return nil
+}
+
+// Submap returns the active submap, empty for the default one. hyprland
+// versions without the submap request report the default.
+func Submap() (string, error) {
+	reply, err := Request("submap")
+	if err != nil {
+		return "", err
+	}
+	switch r := strings.TrimSpace(string(reply)); r {
+	case "default", "unknown request":
+		return "", nil
+	default:
+		return r, nil
+	}
+}
+
+// Batch sends several requests at once (see hyprctl --batch) and returns
+// their replies in order.
+func Batch(cmds ...string) ([]string, error) {
+	reply, err := Request("[[BATCH]]" + strings.Join(cmds, ";"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(reply), "\n\n\n"), nil
+}
+
+type Workspace struct {
+	Id              int    `json:"id"`
+	Name            string `json:"name"`
+	Monitor         string `json:"monitor"`
+	MonitorID       int    `json:"monitorID"`
+	Windows         int    `json:"windows"`
+	Hasfullscreen   bool   `json:"hasfullscreen"`
+	Lastwindow      string `json:"lastwindow"`
+	Lastwindowtitle string `json:"lastwindowtitle"`
+}
+
+func Workspaces() ([]Workspace, error) {
+	var o []Workspace
+	return o, Query("workspaces", &o)
+}
+
+func ActiveWorkspace() (Workspace, error) {
+	var o Workspace
+	return o, Query("activeworkspace", &o)
+}
+
+type ClientWS struct {
+	Id   int
+	Name string
+}
+
+type Client struct {
+	Address          string      `json:"address"`
+	Mapped           bool        `json:"mapped"`
+	Hidden           bool        `json:"hidden"`
+	At               []int       `json:"at"`
+	Size             []int       `json:"size"`
+	Workspace        ClientWS    `json:"workspace"`
+	Floating         bool        `json:"floating"`
+	Pseudo           bool        `json:"pseudo"`
+	Monitor          int         `json:"monitor"`
+	Class            string      `json:"class"`
+	Title            string      `json:"title"`
+	InitialClass     string      `json:"initialClass"`
+	InitialTitle     string      `json:"initialTitle"`
+	Pid              int         `json:"pid"`
+	Xwayland         bool        `json:"xwayland"`
+	Pinned           bool        `json:"pinned"`
+	Fullscreen       int         `json:"fullscreen"`
+	FullscreenClient int         `json:"fullscreenClient"`
+	Grouped          interface{} `json:"grouped"`
+	Tags             interface{} `json:"tags"`
+	Swallowing       string      `json:"swallowing"`
+	FocusHistoryID   int         `json:"focusHistoryID"`
+	InhibitingIdle   bool        `json:"inhibitingIdle"`
+}
+
+func Clients() ([]Client, error) {
+	var o []Client
+	return o, Query("clients", &o)
+}
+
+// ActiveWindow returns the focused client, Address is empty if there is none.
+func ActiveWindow() (Client, error) {
+	var o Client
+	return o, Query("activewindow", &o)
+}
+
+type Monitor struct {
+	Id               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Width            int      `json:"width"`
+	Height           int      `json:"height"`
+	RefreshRate      float64  `json:"refreshRate"`
+	X                int      `json:"x"`
+	Y                int      `json:"y"`
+	Scale            float64  `json:"scale"`
+	Focused          bool     `json:"focused"`
+	ActiveWorkspace  ClientWS `json:"activeWorkspace"`
+	SpecialWorkspace ClientWS `json:"specialWorkspace"`
+}
+
+func Monitors() ([]Monitor, error) {
+	var o []Monitor
+	return o, Query("monitors", &o)
+}
+
+type Layer struct {
+	Address   string `json:"address"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	W         int    `json:"w"`
+	H         int    `json:"h"`
+	Namespace string `json:"namespace"`
+	Pid       int    `json:"pid"`
+}
+
+// Layers returns the layer surfaces of every monitor, keyed by monitor name.
+func Layers() (map[string][]Layer, error) {
+	var o map[string]struct {
+		Levels map[string][]Layer `json:"levels"`
+	}
+	if err := Query("layers", &o); err != nil {
+		return nil, err
+	}
+
+	layers := make(map[string][]Layer, len(o))
+	for mon, l := range o {
+		for _, level := range l.Levels {
+			layers[mon] = append(layers[mon], level...)
+		}
+	}
+	return layers, nil
+}
+
+type Keyboard struct {
+	Address      string `json:"address"`
+	Name         string `json:"name"`
+	Layout       string `json:"layout"` // comma separated codes, like "us,de"
+	Variant      string `json:"variant"`
+	ActiveKeymap string `json:"active_keymap"`
+	Main         bool   `json:"main"`
+}
+
+func Keyboards() ([]Keyboard, error) {
+	var o struct {
+		Keyboards []Keyboard `json:"keyboards"`
+	}
+	return o.Keyboards, Query("devices", &o)
+}
+
+// SwitchXkbLayout switches the layout of a keyboard, or of every one with
+// "all". to is "next", "prev" or a layout index.
+func SwitchXkbLayout(device, to string) error {
+	cmd := "switchxkblayout " + device + " " + to
+	reply, err := Request(cmd)
+	if err != nil {
+		return err
+	}
+	if r := strings.TrimSpace(string(reply)); r != "ok" {
+		return fmt.Errorf("%s: %s", cmd, r)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package hypr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// typed socket2 events, see https://wiki.hyprland.org/IPC/. window addresses
+// get a 0x prefix so they compare equal to Client.Address.
+
+type WorkspaceEvent struct{ Name string }
+type WorkspaceV2Event struct {
+	Id   int
+	Name string
+}
+type FocusedMonEvent struct{ Monitor, Workspace string }
+type FocusedMonV2Event struct {
+	Monitor     string
+	WorkspaceId int
+}
+type ActiveWindowEvent struct{ Class, Title string }
+type ActiveWindowV2Event struct{ Address string } // empty when nothing is focused
+type FullscreenEvent struct{ Fullscreen bool }
+type MonitorRemovedEvent struct{ Name string }
+type MonitorRemovedV2Event struct {
+	Id                int
+	Name, Description string
+}
+type MonitorAddedEvent struct{ Name string }
+type MonitorAddedV2Event struct {
+	Id                int
+	Name, Description string
+}
+type CreateWorkspaceEvent struct{ Name string }
+type CreateWorkspaceV2Event struct {
+	Id   int
+	Name string
+}
+type DestroyWorkspaceEvent struct{ Name string }
+type DestroyWorkspaceV2Event struct {
+	Id   int
+	Name string
+}
+type MoveWorkspaceEvent struct{ Name, Monitor string }
+type MoveWorkspaceV2Event struct {
+	Id            int
+	Name, Monitor string
+}
+type RenameWorkspaceEvent struct {
+	Id      int
+	NewName string
+}
+type ActiveSpecialEvent struct{ Name, Monitor string } // Name is empty when closed
+type ActiveSpecialV2Event struct {
+	Id            int
+	Name, Monitor string
+}
+type ActiveLayoutEvent struct{ Keyboard, Layout string }
+type OpenWindowEvent struct{ Address, Workspace, Class, Title string }
+type CloseWindowEvent struct{ Address string }
+type MoveWindowEvent struct{ Address, Workspace string }
+type MoveWindowV2Event struct {
+	Address     string
+	WorkspaceId int
+	Workspace   string
+}
+type OpenLayerEvent struct{ Namespace string }
+type CloseLayerEvent struct{ Namespace string }
+type SubmapEvent struct{ Name string } // empty for the default submap
+type ChangeFloatingModeEvent struct {
+	Address  string
+	Floating bool
+}
+type UrgentEvent struct{ Address string }
+type ScreencastEvent struct {
+	Active bool
+	Owner  int // 0 monitor share, 1 window share
+}
+type WindowTitleEvent struct{ Address string }
+type WindowTitleV2Event struct{ Address, Title string }
+type ToggleGroupEvent struct {
+	Exists    bool
+	Addresses []string
+}
+type MoveIntoGroupEvent struct{ Address string }
+type MoveOutOfGroupEvent struct{ Address string }
+type IgnoreGroupLockEvent struct{ Enabled bool }
+type LockGroupsEvent struct{ Locked bool }
+type ConfigReloadedEvent struct{}
+type PinEvent struct {
+	Address string
+	Pinned  bool
+}
+type MinimizedEvent struct {
+	Address   string
+	Minimized bool
+}
+type BellEvent struct{ Address string }
+
+// ResyncEvent is EventResync, state should be queried again
+type ResyncEvent struct{}
+
+// Parse decodes e into one of the *Event structs above. unknown events and
+// malformed data return an error, never a panic.
+func (e HyprEvent) Parse() (any, error) {
+	if e.Event == EventResync {
+		return ResyncEvent{}, nil
+	}
+	p, ok := parsers[e.Event]
+	if !ok {
+		return nil, fmt.Errorf("hypr: unknown event %q", e.Event)
+	}
+
+	f := fields{s: e.Data}
+	v := p(&f)
+	if f.err != nil {
+		return nil, fmt.Errorf("hypr: %s>>%s: %w", e.Event, e.Data, f.err)
+	}
+	return v, nil
+}
+
+// fields hands out comma separated values left to right, the last value
+// read gets the rest of the line since titles and names can hold commas
+type fields struct {
+	s   string
+	err error
+}
+
+func (f *fields) str() string {
+	v, rest, _ := strings.Cut(f.s, ",")
+	f.s = rest
+	return v
+}
+
+func (f *fields) rest() string {
+	v := f.s
+	f.s = ""
+	return v
+}
+
+func (f *fields) int() int {
+	v := f.str()
+	n, err := strconv.Atoi(v)
+	if err != nil && f.err == nil {
+		f.err = fmt.Errorf("bad number %q", v)
+	}
+	return n
+}
+
+// activespecialv2 sends an empty id when the special workspace closes
+func (f *fields) intOrZero() int {
+	v, rest, _ := strings.Cut(f.s, ",")
+	if v == "" {
+		f.s = rest
+		return 0
+	}
+	return f.int()
+}
+
+func (f *fields) bool() bool { return f.str() == "1" }
+
+func (f *fields) addr() string { return address(f.str()) }
+
+func address(a string) string {
+	if a == "" || strings.HasPrefix(a, "0x") {
+		return a
+	}
+	return "0x" + a
+}
+
+var parsers = map[string]func(f *fields) any{
+	"workspace":   func(f *fields) any { return WorkspaceEvent{f.rest()} },
+	"workspacev2": func(f *fields) any { return WorkspaceV2Event{f.int(), f.rest()} },
+	"focusedmon":  func(f *fields) any { return FocusedMonEvent{f.str(), f.rest()} },
+	"focusedmonv2": func(f *fields) any {
+		return FocusedMonV2Event{f.str(), f.int()}
+	},
+	"activewindow":     func(f *fields) any { return ActiveWindowEvent{f.str(), f.rest()} },
+	"activewindowv2":   func(f *fields) any { return ActiveWindowV2Event{f.addr()} },
+	"fullscreen":       func(f *fields) any { return FullscreenEvent{f.bool()} },
+	"monitorremoved":   func(f *fields) any { return MonitorRemovedEvent{f.rest()} },
+	"monitorremovedv2": func(f *fields) any { return MonitorRemovedV2Event{f.int(), f.str(), f.rest()} },
+	"monitoradded":     func(f *fields) any { return MonitorAddedEvent{f.rest()} },
+	"monitoraddedv2":   func(f *fields) any { return MonitorAddedV2Event{f.int(), f.str(), f.rest()} },
+
+	"createworkspace":    func(f *fields) any { return CreateWorkspaceEvent{f.rest()} },
+	"createworkspacev2":  func(f *fields) any { return CreateWorkspaceV2Event{f.int(), f.rest()} },
+	"destroyworkspace":   func(f *fields) any { return DestroyWorkspaceEvent{f.rest()} },
+	"destroyworkspacev2": func(f *fields) any { return DestroyWorkspaceV2Event{f.int(), f.rest()} },
+	"moveworkspace":      func(f *fields) any { return MoveWorkspaceEvent{f.str(), f.rest()} },
+	"moveworkspacev2":    func(f *fields) any { return MoveWorkspaceV2Event{f.int(), f.str(), f.rest()} },
+	"renameworkspace":    func(f *fields) any { return RenameWorkspaceEvent{f.int(), f.rest()} },
+	"activespecial":      func(f *fields) any { return ActiveSpecialEvent{f.str(), f.rest()} },
+	"activespecialv2":    func(f *fields) any { return ActiveSpecialV2Event{f.intOrZero(), f.str(), f.rest()} },
+	"activelayout":       func(f *fields) any { return ActiveLayoutEvent{f.str(), f.rest()} },
+
+	"openwindow": func(f *fields) any {
+		return OpenWindowEvent{f.addr(), f.str(), f.str(), f.rest()}
+	},
+	"closewindow":  func(f *fields) any { return CloseWindowEvent{address(f.rest())} },
+	"movewindow":   func(f *fields) any { return MoveWindowEvent{f.addr(), f.rest()} },
+	"movewindowv2": func(f *fields) any { return MoveWindowV2Event{f.addr(), f.int(), f.rest()} },
+	"openlayer":    func(f *fields) any { return OpenLayerEvent{f.rest()} },
+	"closelayer":   func(f *fields) any { return CloseLayerEvent{f.rest()} },
+	"submap":       func(f *fields) any { return SubmapEvent{f.rest()} },
+	"changefloatingmode": func(f *fields) any {
+		return ChangeFloatingModeEvent{f.addr(), f.bool()}
+	},
+	"urgent":        func(f *fields) any { return UrgentEvent{address(f.rest())} },
+	"screencast":    func(f *fields) any { return ScreencastEvent{f.bool(), f.int()} },
+	"windowtitle":   func(f *fields) any { return WindowTitleEvent{address(f.rest())} },
+	"windowtitlev2": func(f *fields) any { return WindowTitleV2Event{f.addr(), f.rest()} },
+	"togglegroup": func(f *fields) any {
+		ev := ToggleGroupEvent{Exists: f.bool()}
+		for _, a := range strings.Split(f.rest(), ",") {
+			if a != "" {
+				ev.Addresses = append(ev.Addresses, address(a))
+			}
+		}
+		return ev
+	},
+	"moveintogroup":   func(f *fields) any { return MoveIntoGroupEvent{address(f.rest())} },
+	"moveoutofgroup":  func(f *fields) any { return MoveOutOfGroupEvent{address(f.rest())} },
+	"ignoregrouplock": func(f *fields) any { return IgnoreGroupLockEvent{f.bool()} },
+	"lockgroups":      func(f *fields) any { return LockGroupsEvent{f.bool()} },
+	"configreloaded":  func(f *fields) any { return ConfigReloadedEvent{} },
+	"pin":             func(f *fields) any { return PinEvent{f.addr(), f.bool()} },
+	"minimized":       func(f *fields) any { return MinimizedEvent{f.addr(), f.bool()} },
+	"bell":            func(f *fields) any { return BellEvent{address(f.rest())} },
+}
@@ -8,14 +8,16 @@ package hypr
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/nekorg/pawbar/internal/services"
+	"github.com/nekorg/pawbar/internal/utils"
 )
 
 func Register() (*Service, bool) {
@@ -25,14 +27,29 @@ func Register() (*Service, bool) {
 	return nil, false
 }
 
+// EventResync is sent to every registered channel after the event socket
+// reconnects or events were dropped for a slow consumer. state built from
+// events should be re-queried.
+const EventResync = "pawbar>resync"
+
+const (
+	minBackoff = 250 * time.Millisecond
+	maxBackoff = 10 * time.Second
+)
+
 type Service struct {
-	callbacks map[string][]chan<- HyprEvent
-	running   bool
+	mu      sync.Mutex
+	subs    []*services.Subscriber[HyprEvent]
+	running bool
+	stop    chan struct{}
+	conn    net.Conn
 }
 
 func (h *Service) Name() string { return "hypr" }
 
 func (h *Service) Start() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if h.running {
 		return nil
 	}
@@ -41,165 +58,161 @@ func (h *Service) Start() error {
 		return fmt.Errorf("Hyprland is not running.")
 	}
 
-	h.callbacks = make(map[string][]chan<- HyprEvent)
-	go h.run()
+	h.stop = make(chan struct{})
+	for _, sub := range h.subs {
+		go sub.Forward(h.stop)
+	}
+	go h.run(h.stop)
 	h.running = true
 	return nil
 }
 
 func (h *Service) Stop() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.running {
+		return nil
+	}
+
+	close(h.stop)
+	if h.conn != nil {
+		h.conn.Close()
+	}
+	h.running = false
 	return nil
 }
 
+// RegisterChannel sends event (EventResync is always sent) to ch. a channel
+// can be registered for several events, it gets them in order.
 func (h *Service) RegisterChannel(event string, ch chan<- HyprEvent) {
-	h.callbacks[event] = append(h.callbacks[event], ch)
-}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-func (h *Service) run() {
-	_, sockaddr2 := GetHyprSocketAddrs()
+	for _, sub := range h.subs {
+		if sub.Out == ch {
+			sub.Events[event] = true
+			return
+		}
+	}
 
-	sock2, err := net.Dial("unix", sockaddr2)
-	if err != nil {
-		panic(err)
+	sub := services.NewSubscriber(ch, HyprEvent{Event: EventResync})
+	sub.Events[event] = true
+	h.subs = append(h.subs, sub)
+	if h.stop != nil {
+		go sub.Forward(h.stop)
 	}
-	defer sock2.Close()
-
-	scanner := bufio.NewScanner(sock2)
-	for scanner.Scan() {
-		e := NewHyprEvent(scanner.Text())
-		c, ok := h.callbacks[e.Event]
-		if ok {
-			for _, ch := range c {
-				ch <- e
-			}
+}
+
+func (h *Service) dispatch(e HyprEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subs {
+		if e.Event == EventResync || sub.Events[e.Event] {
+			sub.Push(e)
 		}
 	}
 }
 
-func GetHyprSocketAddrs() (string, string) {
-	instance_signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
-	runtime_dir := os.Getenv("XDG_RUNTIME_DIR")
-	socket_addr := path.Join(runtime_dir, "/hypr", instance_signature)
+// run keeps socket2 connected, retrying with backoff when hyprland goes
+// away, until the service is stopped
+func (h *Service) run(stop chan struct{}) {
+	backoff := minBackoff
+	connected := false
+	for {
+		_, sockaddr2 := GetHyprSocketAddrs()
+		sock2, err := net.Dial("unix", sockaddr2)
+		if err != nil {
+			if connected {
+				utils.Logger.Printf("hypr: %v, retrying in %v\n", err, backoff)
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			findInstance()
+			continue
+		}
 
-	return path.Join(socket_addr, "/.socket.sock"), path.Join(socket_addr, "/.socket2.sock")
-}
+		h.mu.Lock()
+		select {
+		case <-stop:
+			h.mu.Unlock()
+			sock2.Close()
+			return
+		default:
+		}
+		h.conn = sock2
+		h.mu.Unlock()
 
-type HyprEvent struct {
-	Event string
-	Data  string
-}
+		if connected {
+			utils.Logger.Println("hypr: reconnected to event socket")
+			h.dispatch(HyprEvent{Event: EventResync})
+		}
+		connected = true
+		backoff = minBackoff
 
-func NewHyprEvent(s string) HyprEvent {
-	e, d, _ := strings.Cut(s, ">>")
-	return HyprEvent{e, strings.Trim(d, " \n")}
-}
+		scanner := bufio.NewScanner(sock2)
+		scanner.Buffer(make([]byte, 0, 4096), 1<<20) // titles can be long
+		for scanner.Scan() {
+			h.dispatch(NewHyprEvent(scanner.Text()))
+		}
+		sock2.Close()
 
-type Workspace struct {
-	Id              int    `json:"id"`
-	Name            string `json:"name"`
-	Monitor         string `json:"monitor"`
-	MonitorID       int    `json:"monitorID"`
-	Windows         int    `json:"windows"`
-	Hasfullscreen   bool   `json:"hasfullscreen"`
-	Lastwindow      string `json:"lastwindow"`
-	Lastwindowtitle string `json:"lastwindowtitle"`
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		utils.Logger.Printf("hypr: event socket closed (%v), reconnecting\n", scanner.Err())
+	}
 }
 
-func GetWorkspaces() []Workspace {
-	sockaddr1, _ := GetHyprSocketAddrs()
-	sock, err := net.Dial("unix", sockaddr1)
-	if err != nil {
-		panic(err)
+// a restarted hyprland has a new instance signature, pick the newest
+// instance that has an event socket
+func findInstance() {
+	_, sockaddr2 := GetHyprSocketAddrs()
+	if _, err := os.Stat(sockaddr2); err == nil {
+		return
 	}
-	defer sock.Close()
-	scanner := json.NewDecoder(sock)
 
-	sock.Write([]byte("-j/workspaces"))
-	var o []Workspace
-
-	err = scanner.Decode(&o)
+	dir := path.Join(os.Getenv("XDG_RUNTIME_DIR"), "hypr")
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		panic(err)
+		return
 	}
-	return o
-}
 
-func GetActiveWorkspace() Workspace {
-	sockaddr1, _ := GetHyprSocketAddrs()
-	sock, err := net.Dial("unix", sockaddr1)
-	if err != nil {
-		panic(err)
+	newest, newestTime := "", time.Time{}
+	for _, e := range entries {
+		fi, err := os.Stat(path.Join(dir, e.Name(), ".socket2.sock"))
+		if err != nil {
+			continue
+		}
+		if fi.ModTime().After(newestTime) {
+			newest, newestTime = e.Name(), fi.ModTime()
+		}
 	}
-	defer sock.Close()
-	scanner := json.NewDecoder(sock)
-
-	sock.Write([]byte("-j/activeworkspace"))
-	var o Workspace
-
-	err = scanner.Decode(&o)
-	if err != nil {
-		panic(err)
+	if newest != "" {
+		utils.Logger.Printf("hypr: switching to instance %s\n", newest)
+		os.Setenv("HYPRLAND_INSTANCE_SIGNATURE", newest)
 	}
-	return o
 }
 
-type ClientWS struct {
-	Id   int
-	Name string
-}
+func GetHyprSocketAddrs() (string, string) {
+	instance_signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	runtime_dir := os.Getenv("XDG_RUNTIME_DIR")
+	socket_addr := path.Join(runtime_dir, "/hypr", instance_signature)
 
-type Client struct {
-	Address          string      `json:"address"`
-	Mapped           bool        `json:"mapped"`
-	Hidden           bool        `json:"hidden"`
-	At               []int       `json:"at"`
-	Size             []int       `json:"size"`
-	Workspace        ClientWS    `json:"workspace"`
-	Floating         bool        `json:"floating"`
-	Pseudo           bool        `json:"pseudo"`
-	Monitor          int         `json:"monitor"`
-	Class            string      `json:"class"`
-	Title            string      `json:"title"`
-	InitialClass     string      `json:"initialClass"`
-	InitialTitle     string      `json:"initialTitle"`
-	Pid              int         `json:"pid"`
-	Xwayland         bool        `json:"xwayland"`
-	Pinned           bool        `json:"pinned"`
-	Fullscreen       int         `json:"fullscreen"`
-	FullscreenClient int         `json:"fullscreenClient"`
-	Grouped          interface{} `json:"grouped"`
-	Tags             interface{} `json:"tags"`
-	Swallowing       string      `json:"swallowing"`
-	FocusHistoryID   int         `json:"focusHistoryID"`
-	InhibitingIdle   bool        `json:"inhibitingIdle"`
+	return path.Join(socket_addr, "/.socket.sock"), path.Join(socket_addr, "/.socket2.sock")
 }
 
-func GetClients() []Client {
-	sockaddr1, _ := GetHyprSocketAddrs()
-	sock, err := net.Dial("unix", sockaddr1)
-	if err != nil {
-		panic(err)
-	}
-	defer sock.Close()
-	scanner := json.NewDecoder(sock)
-
-	sock.Write([]byte("-j/clients"))
-	var o []Client
-
-	err = scanner.Decode(&o)
-	if err != nil {
-		panic(err)
-	}
-	return o
+type HyprEvent struct {
+	Event string
+	Data  string
 }
 
-func GoToWorkspace(name string) {
-	sockaddr1, _ := GetHyprSocketAddrs()
-	sock, err := net.Dial("unix", sockaddr1)
-	if err != nil {
-		panic(err)
-	}
-	defer sock.Close()
-
-	sock.Write([]byte("/dispatch workspace " + name))
+func NewHyprEvent(s string) HyprEvent {
+	e, d, _ := strings.Cut(s, ">>")
+	return HyprEvent{e, strings.Trim(d, " \n")}
 }
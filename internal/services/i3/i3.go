@@ -7,38 +7,52 @@
 package i3
 
 import (
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"os"
-	"os/exec"
+	"sync"
+	"time"
 
 	"github.com/nekorg/pawbar/internal/services"
 	"github.com/nekorg/pawbar/internal/utils"
 )
 
+// events that can be passed to RegisterChannel, named like i3's own. what
+// the channel receives is the matching *Event type below
 const (
-	ipcMagic                      = "i3-ipc"
-	I3_IPC_MESSAGE_TYPE_SUBSCRIBE = 2
-	IPC_GET_WORKSPACES            = 1
-	msgTypeGetTree                = 4
+	EventWorkspace       = "workspace"
+	EventOutput          = "output"
+	EventMode            = "mode"
+	EventWindow          = "window"
+	EventBarconfigUpdate = "barconfig_update"
+	EventBinding         = "binding"
+	EventShutdown        = "shutdown"
+	EventInput           = "input" // sway only
 )
 
-var (
-	event  I3Event
-	wevent I3WEvent
-)
-
-type Service struct {
-	callbacks map[string][]chan<- interface{}
-	running   bool
+// event type numbers, as i3 and sway define them
+var eventNames = map[uint32]string{
+	0:  EventWorkspace,
+	1:  EventOutput,
+	2:  EventMode,
+	3:  EventWindow,
+	4:  EventBarconfigUpdate,
+	5:  EventBinding,
+	6:  EventShutdown,
+	21: EventInput,
 }
 
+const (
+	minBackoff = 250 * time.Millisecond
+	maxBackoff = 10 * time.Second
+)
+
 type WinInfo struct {
 	Class string `json:"class"`
 	Title string `json:"title"`
+	// set by WorkspaceWindows for windows that belong to the scratchpad
+	Scratchpad bool `json:"-"`
 }
 
 type WsInfo struct {
@@ -54,15 +68,11 @@ type WsIdentity struct {
 	Nodes           []WsInfo `json:"nodes"`
 }
 
-type I3Event struct {
-	Change  string     `json:"change"`
-	Current WsIdentity `json:"current"`
-	Old     WsIdentity `json:"old"`
-}
-
 type Workspace struct {
 	Id      int    `json:"num"`
 	Name    string `json:"name"`
+	Output  string `json:"output"`
+	Visible bool   `json:"visible"` // shown on its output
 	Focused bool   `json:"focused"`
 	Urgent  bool   `json:"urgent"`
 }
@@ -73,6 +83,8 @@ type WindowProperties struct {
 }
 
 type I3Node struct {
+	Type             string            `json:"type"`
+	ScratchpadState  string            `json:"scratchpad_state"`
 	Focused          bool              `json:"focused"`
 	Nodes            []I3Node          `json:"nodes"`
 	FloatingNodes    []I3Node          `json:"floating_nodes"`
@@ -81,11 +93,119 @@ type I3Node struct {
 	AppId            string            `json:"app_id"`
 }
 
-func Register() (*Service, bool) {
-	if s, ok := services.Ensure("i3", func() services.Service { return &Service{} }).(*Service); ok {
-		return s, true
+// I3Event is a workspace event
+type I3Event struct {
+	Change  string     `json:"change"`
+	Current WsIdentity `json:"current"`
+	Old     WsIdentity `json:"old"`
+}
+
+// I3WEvent is a window event
+type I3WEvent struct {
+	Change    string    `json:"change"`
+	Container Container `json:"container"`
+}
+
+type ModeEvent struct {
+	Change      string `json:"change"` // name of the mode, "default" when left
+	PangoMarkup bool   `json:"pango_markup"`
+}
+
+type OutputEvent struct {
+	Change string `json:"change"`
+}
+
+type Binding struct {
+	Command        string   `json:"command"`
+	EventStateMask []string `json:"event_state_mask"`
+	InputCode      int      `json:"input_code"`
+	Symbol         string   `json:"symbol"`
+	InputType      string   `json:"input_type"`
+}
+
+type BindingEvent struct {
+	Change  string  `json:"change"`
+	Binding Binding `json:"binding"`
+}
+
+type ShutdownEvent struct {
+	Change string `json:"change"` // "exit" or "restart"
+}
+
+type BarconfigUpdateEvent struct {
+	Id          string `json:"id"`
+	Mode        string `json:"mode"`
+	HiddenState string `json:"hidden_state"`
+}
+
+// Input is a sway input device, the xkb fields are only set for keyboards
+type Input struct {
+	Identifier           string   `json:"identifier"`
+	Name                 string   `json:"name"`
+	Type                 string   `json:"type"`
+	XkbLayoutNames       []string `json:"xkb_layout_names"`
+	XkbActiveLayoutName  string   `json:"xkb_active_layout_name"`
+	XkbActiveLayoutIndex int      `json:"xkb_active_layout_index"`
+}
+
+type InputEvent struct {
+	Change string `json:"change"`
+	Input  Input  `json:"input"`
+}
+
+// ResyncEvent is sent to every channel after the event connection came back
+// or events were dropped for a slow consumer, state should be queried again
+type ResyncEvent struct{}
+
+// ErrorEvent is sent to every channel when the event connection is lost,
+// the service keeps trying to reconnect
+type ErrorEvent struct{ Err error }
+
+func decodeEvent(name string, payload []byte) (any, error) {
+	var v any
+	switch name {
+	case EventWorkspace:
+		v = &I3Event{}
+	case EventWindow:
+		v = &I3WEvent{}
+	case EventMode:
+		v = &ModeEvent{}
+	case EventOutput:
+		v = &OutputEvent{}
+	case EventBinding:
+		v = &BindingEvent{}
+	case EventShutdown:
+		v = &ShutdownEvent{}
+	case EventBarconfigUpdate:
+		v = &BarconfigUpdateEvent{}
+	case EventInput:
+		v = &InputEvent{}
+	default:
+		return nil, fmt.Errorf("unknown event %q", name)
 	}
-	return nil, false
+	if err := json.Unmarshal(payload, v); err != nil {
+		return nil, fmt.Errorf("error unmarshaling %s event: %w", name, err)
+	}
+	// hand out values, not pointers
+	switch e := v.(type) {
+	case *I3Event:
+		return *e, nil
+	case *I3WEvent:
+		return *e, nil
+	case *ModeEvent:
+		return *e, nil
+	case *OutputEvent:
+		return *e, nil
+	case *BindingEvent:
+		return *e, nil
+	case *ShutdownEvent:
+		return *e, nil
+	case *BarconfigUpdateEvent:
+		return *e, nil
+	case *InputEvent:
+		return *e, nil
+	}
+	return nil, nil
 }
 
 type Container struct {
@@ -93,244 +213,255 @@ type Container struct {
 	Type string `json:"type"`
 }
 
-type I3WEvent struct {
-	Change    string    `json:"change"`
-	Container Container `json:"container"`
+func Register() (*Service, bool) {
+	if s, ok := services.Ensure("i3", func() services.Service { return &Service{} }).(*Service); ok {
+		return s, true
+	}
+	return nil, false
+}
+
+type Service struct {
+	mu      sync.Mutex
+	subs    []*services.Subscriber[interface{}]
+	wanted  map[string]bool // events subscribed to
+	running bool
+	stop    chan struct{}
+	conn    net.Conn
 }
 
 func (i *Service) Name() string { return "i3" }
 
 func (i *Service) Start() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	if i.running {
 		return nil
 	}
 
-	if os.Getenv("I3SOCK") == "" {
+	if socketPath() == "" {
 		return fmt.Errorf("i3 or sway is not running.")
 	}
 
-	i.callbacks = make(map[string][]chan<- interface{})
-	go i.sockMsg()
+	if i.wanted == nil {
+		i.wanted = make(map[string]bool)
+	}
+	i.stop = make(chan struct{})
+	for _, sub := range i.subs {
+		go sub.Forward(i.stop)
+	}
+	go i.run(i.stop)
 	i.running = true
 	return nil
 }
 
 func (i *Service) Stop() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.running {
+		return nil
+	}
+
+	close(i.stop)
+	if i.conn != nil {
+		i.conn.Close()
+	}
+	i.running = false
 	return nil
 }
 
+// RegisterChannel sends event (one of the Event* constants) to ch, along
+// with ResyncEvent and ErrorEvent. a channel can be registered for several
+// events, it gets them in order.
 func (i *Service) RegisterChannel(event string, ch chan<- interface{}) {
-	i.callbacks[event] = append(i.callbacks[event], ch)
-}
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-func connectToI3() (net.Conn, error) {
-	sockPath := os.Getenv("I3SOCK")
-	if sockPath == "" {
-		return nil, fmt.Errorf("I3SOCK environment variable is not set")
+	if !i.wanted[event] {
+		if i.wanted == nil {
+			i.wanted = make(map[string]bool)
+		}
+		i.wanted[event] = true
+		// replies to this come on the event connection, run ignores them
+		if i.conn != nil {
+			if err := subscribe(i.conn, []string{event}); err != nil {
+				utils.Logger.Printf("i3: subscribing to %s: %v\n", event, err)
+			}
+		}
 	}
 
-	conn, err := net.Dial("unix", sockPath)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to i3 socket: %v", err)
+	for _, sub := range i.subs {
+		if sub.Out == ch {
+			sub.Events[event] = true
+			return
+		}
 	}
 
-	return conn, nil
-}
-
-func sendI3Message(conn net.Conn, messageType uint32, payload []byte) error {
-	header := make([]byte, 14)
-	copy(header[:6], []byte(ipcMagic))
-	binary.LittleEndian.PutUint32(header[6:10], uint32(len(payload)))
-	binary.LittleEndian.PutUint32(header[10:14], messageType)
-
-	sendMsg := append(header, payload...)
-
-	if _, err := conn.Write(sendMsg); err != nil {
-		return fmt.Errorf("failed to write data: %w", err)
+	sub := services.NewSubscriber[interface{}](ch, ResyncEvent{})
+	sub.Events[event] = true
+	i.subs = append(i.subs, sub)
+	if i.stop != nil {
+		go sub.Forward(i.stop)
 	}
-	return nil
 }
 
-func readI3Ack(conn net.Conn) (string, error) {
-	const headerSize = 14
-	ackHeader := make([]byte, headerSize)
-	if _, err := io.ReadFull(conn, ackHeader); err != nil {
-		return "", fmt.Errorf("error reading ack header: %v", err)
+func (i *Service) dispatch(name string, e interface{}) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, sub := range i.subs {
+		if name == "" || sub.Events[name] {
+			sub.Push(e)
+		}
 	}
+}
 
-	if string(ackHeader[0:6]) != "i3-ipc" {
-		return "", fmt.Errorf("invalid magic in header: expected 'i3-ipc', got '%s'", string(ackHeader[0:6]))
+func subscribe(conn net.Conn, events []string) error {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return err
 	}
+	return sendI3Message(conn, msgTypeSubscribe, payload)
+}
 
-	ackLen := binary.LittleEndian.Uint32(ackHeader[6:10])
-	ackPayload := make([]byte, ackLen)
-	if _, err := io.ReadFull(conn, ackPayload); err != nil {
-		return "", fmt.Errorf("error reading ack payload: %v", err)
-	}
+// run keeps the event connection up, retrying with backoff, until the
+// service is stopped
+func (i *Service) run(stop chan struct{}) {
+	backoff := minBackoff
+	connected := false
+	for {
+		conn, err := i.connect()
+		if err != nil {
+			if connected {
+				utils.Logger.Printf("i3: %v, retrying in %v\n", err, backoff)
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
 
-	return string(ackPayload), nil
-}
+		i.mu.Lock()
+		select {
+		case <-stop:
+			i.mu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		i.conn = conn
+		i.mu.Unlock()
 
-func readResponse(conn net.Conn) (uint32, []byte, error) {
-	responseHeader := make([]byte, 14)
-	if _, err := io.ReadFull(conn, responseHeader); err != nil {
-		return 13, nil, fmt.Errorf("error reading response header: %v", err)
-	}
-	if string(responseHeader[:6]) != "i3-ipc" {
-		return 13, nil, fmt.Errorf("invalid response magic: expected '%s', got '%s'", ipcMagic, string(responseHeader[:6]))
-	}
+		if connected {
+			utils.Logger.Println("i3: reconnected")
+			i.dispatch("", ResyncEvent{})
+		}
+		connected = true
+		backoff = minBackoff
 
-	payloadLength := binary.LittleEndian.Uint32(responseHeader[6:10])
-	payloadData := make([]byte, payloadLength)
+		err = i.read(conn)
+		conn.Close()
 
-	responseType := binary.LittleEndian.Uint32(responseHeader[10:14])
+		i.mu.Lock()
+		i.conn = nil
+		i.mu.Unlock()
 
-	if _, err := io.ReadFull(conn, payloadData); err != nil {
-		return 13, nil, fmt.Errorf("error reading payload data: %v", err)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		utils.Logger.Printf("i3: event connection lost: %v\n", err)
+		i.dispatch("", ErrorEvent{err})
 	}
-
-	return responseType, payloadData, nil
 }
 
-func (i *Service) sockMsg() {
+// dials and subscribes to every event someone registered for
+func (i *Service) connect() (net.Conn, error) {
 	conn, err := connectToI3()
 	if err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
+		return nil, err
 	}
-	defer conn.Close()
 
-	subscription := []string{"window", "workspace"}
-	payload, err := json.Marshal(subscription)
-	if err != nil {
-		utils.Logger.Printf("Error marshaling subscription payload: %v\n", err)
-		os.Exit(1)
+	i.mu.Lock()
+	events := make([]string, 0, len(i.wanted))
+	for e := range i.wanted {
+		events = append(events, e)
 	}
+	i.mu.Unlock()
 
-	if err := sendI3Message(conn, I3_IPC_MESSAGE_TYPE_SUBSCRIBE, payload); err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
-	}
-
-	ack, err := readI3Ack(conn)
-	if err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
+	if len(events) > 0 {
+		if err := subscribe(conn, events); err != nil {
+			conn.Close()
+			return nil, err
+		}
 	}
+	return conn, nil
+}
 
-	utils.Logger.Println("Subscription Acknowledgment:", ack)
-
+func (i *Service) read(conn net.Conn) error {
 	for {
-		eventType, eventPayload, err := readResponse(conn)
+		eventType, payload, err := readResponse(conn)
 		if err != nil {
-			utils.Logger.Println("Error reading response:", err)
-			break
+			return err
 		}
 
-		switch eventType {
-		case 0x80000000:
-			if err := json.Unmarshal(eventPayload, &event); err != nil {
-				utils.Logger.Println("Error unmarshaling event:", err)
-				continue
-			}
-
-			if chans, ok := i.callbacks["workspaces"]; ok {
-				for _, ch := range chans {
-					ch <- event
-				}
-			}
-		case 0x80000003:
-			if err := json.Unmarshal(eventPayload, &wevent); err != nil {
-				utils.Logger.Println("Error unmarshaling event:", err)
-				continue
-			}
-
-			if chans, ok := i.callbacks["activeWindow"]; ok {
-				for _, ch := range chans {
-					ch <- wevent
-				}
-			}
+		// subscribe replies
+		if eventType&eventBit == 0 {
+			continue
 		}
-	}
-}
-
-func GetWorkspaces() []Workspace {
-	conn, err := connectToI3()
-	if err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
-	}
-	defer conn.Close()
 
-	payload := []byte("")
+		name, ok := eventNames[eventType&^eventBit]
+		if !ok {
+			continue
+		}
 
-	if err := sendI3Message(conn, IPC_GET_WORKSPACES, payload); err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
-	}
+		ev, err := decodeEvent(name, payload)
+		if err != nil {
+			utils.Logger.Println("i3:", err)
+			continue
+		}
+		i.dispatch(name, ev)
 
-	eventType, eventPayload, err := readResponse(conn)
-	utils.Logger.Println("event of type:", eventType)
-	if err != nil {
-		utils.Logger.Println(err)
-		return nil
+		// i3 closes the connection right after, the reconnect picks up the
+		// restarted instance
+		if sd, ok := ev.(ShutdownEvent); ok {
+			return fmt.Errorf("i3 shutdown (%s)", sd.Change)
+		}
 	}
+}
 
+func GetWorkspaces() ([]Workspace, error) {
 	var workspaces []Workspace
-	if err = json.Unmarshal(eventPayload, &workspaces); err != nil {
-		utils.Logger.Println("Error unmarshaling JSON:", err)
-		return nil
-	}
-
-	return workspaces
+	return workspaces, query(msgTypeGetWorkspaces, &workspaces)
 }
 
-func GoToWorkspace(name string) {
-	cmd := exec.Command("i3-msg", "workspace", name)
-	if err := cmd.Run(); err != nil {
-		utils.Logger.Printf("Error executing command: %v\n", err)
-		os.Exit(1)
-	}
+func GoToWorkspace(name string) error {
+	return RunCommand("workspace " + Quote(name))
 }
 
-func GetActiveWorkspace() Workspace {
-	workspaces := GetWorkspaces()
+func GetActiveWorkspace() (Workspace, error) {
+	workspaces, err := GetWorkspaces()
+	if err != nil {
+		return Workspace{}, err
+	}
 	for _, ws := range workspaces {
 		if ws.Focused {
-			return ws
+			return ws, nil
 		}
 	}
-	return Workspace{}
+	return Workspace{}, nil
 }
 
 var isSway = os.Getenv("SWAYSOCK") != ""
 
-func GetTitleClass() (string, string) {
-	conn, err := connectToI3()
-	if err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
-	}
-	defer conn.Close()
-
-	payload := []byte("")
-
-	if err := sendI3Message(conn, msgTypeGetTree, payload); err != nil {
-		utils.Logger.Println(err)
-		os.Exit(1)
-	}
-
-	eventType, eventPayload, err := readResponse(conn)
-	utils.Logger.Println("event of type:", eventType)
-	if err != nil {
-		utils.Logger.Println(err)
-		return "", ""
-	}
-
+// GetTitleClass returns the class (app_id on sway) and title of the
+// focused window.
+func GetTitleClass() (string, string, error) {
 	var root I3Node
-	if err := json.Unmarshal(eventPayload, &root); err != nil {
-		utils.Logger.Printf("Failed to parse JSON: %v\n", err)
-		return "", ""
+	if err := query(msgTypeGetTree, &root); err != nil {
+		return "", "", err
 	}
 
 	var focusedProps *WindowProperties
@@ -361,12 +492,88 @@ func GetTitleClass() (string, string) {
 	findFocused(&root)
 
 	if isSway {
-		return appid, name
+		return appid, name, nil
 	}
 
 	if focusedProps == nil {
-		return "", ""
+		return "", "", nil
+	}
+
+	return focusedProps.Class, focusedProps.Title, nil
+}
+
+// ScratchpadWorkspace is the hidden workspace scratchpad windows live in
+// while they aren't shown.
+const ScratchpadWorkspace = "__i3_scratch"
+
+// WorkspaceWindows returns the windows on each workspace, keyed by the
+// workspace name, hidden scratchpad windows are under ScratchpadWorkspace.
+// Class is the app_id for wayland windows on sway.
+func WorkspaceWindows() (map[string][]WinInfo, error) {
+	var root I3Node
+	if err := query(msgTypeGetTree, &root); err != nil {
+		return nil, err
+	}
+
+	windows := make(map[string][]WinInfo)
+	var walk func(n *I3Node, ws string, scratch bool)
+	walk = func(n *I3Node, ws string, scratch bool) {
+		if n.Type == "workspace" {
+			ws = n.Name
+		}
+		// i3 puts the state on the floating container around the window
+		if n.ScratchpadState != "" && n.ScratchpadState != "none" {
+			scratch = true
+		}
+		if ws != "" && len(n.Nodes) == 0 && len(n.FloatingNodes) == 0 {
+			switch {
+			case n.AppId != "":
+				windows[ws] = append(windows[ws], WinInfo{Class: n.AppId, Title: n.Name, Scratchpad: scratch})
+			case n.WindowProperties != nil:
+				windows[ws] = append(windows[ws], WinInfo{Class: n.WindowProperties.Class, Title: n.Name, Scratchpad: scratch})
+			}
+		}
+		for i := range n.Nodes {
+			walk(&n.Nodes[i], ws, scratch)
+		}
+		for i := range n.FloatingNodes {
+			walk(&n.FloatingNodes[i], ws, scratch)
+		}
 	}
+	walk(&root, "", false)
+	return windows, nil
+}
+
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type Mode struct {
+	Width   int `json:"width"`
+	Height  int `json:"height"`
+	Refresh int `json:"refresh"` // mHz
+}
+
+// Output is what GET_OUTPUTS reports, Scale and CurrentMode are sway only
+type Output struct {
+	Name        string  `json:"name"`
+	Active      bool    `json:"active"`
+	Focused     bool    `json:"focused"`
+	Scale       float64 `json:"scale"`
+	Rect        Rect    `json:"rect"`
+	CurrentMode Mode    `json:"current_mode"`
+}
+
+func GetOutputs() ([]Output, error) {
+	var outputs []Output
+	return outputs, query(msgTypeGetOutputs, &outputs)
+}
 
-	return focusedProps.Class, focusedProps.Title
+// GetInputs lists sway's input devices, it fails on i3.
+func GetInputs() ([]Input, error) {
+	var inputs []Input
+	return inputs, query(msgTypeGetInputs, &inputs)
 }
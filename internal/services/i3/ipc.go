@@ -0,0 +1,173 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package i3
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	ipcMagic = "i3-ipc"
+
+	msgTypeRunCommand      = 0
+	msgTypeGetWorkspaces   = 1
+	msgTypeSubscribe       = 2
+	msgTypeGetOutputs      = 3
+	msgTypeGetTree         = 4
+	msgTypeGetBindingState = 12
+	msgTypeGetInputs       = 100 // sway only
+
+	eventBit = 0x80000000
+)
+
+func socketPath() string {
+	if p := os.Getenv("I3SOCK"); p != "" {
+		return p
+	}
+	return os.Getenv("SWAYSOCK")
+}
+
+func connectToI3() (net.Conn, error) {
+	sockPath := socketPath()
+	if sockPath == "" {
+		return nil, fmt.Errorf("neither I3SOCK nor SWAYSOCK is set")
+	}
+
+	conn, err := net.Dial("unix", sockPath)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to i3 socket: %w", err)
+	}
+
+	return conn, nil
+}
+
+func sendI3Message(conn net.Conn, messageType uint32, payload []byte) error {
+	header := make([]byte, 14)
+	copy(header[:6], []byte(ipcMagic))
+	binary.LittleEndian.PutUint32(header[6:10], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[10:14], messageType)
+
+	sendMsg := append(header, payload...)
+
+	if _, err := conn.Write(sendMsg); err != nil {
+		return fmt.Errorf("failed to write data: %w", err)
+	}
+	return nil
+}
+
+func readResponse(conn net.Conn) (uint32, []byte, error) {
+	responseHeader := make([]byte, 14)
+	if _, err := io.ReadFull(conn, responseHeader); err != nil {
+		return 0, nil, fmt.Errorf("error reading response header: %w", err)
+	}
+	if string(responseHeader[:6]) != ipcMagic {
+		return 0, nil, fmt.Errorf("invalid response magic: expected '%s', got '%s'", ipcMagic, string(responseHeader[:6]))
+	}
+
+	payloadLength := binary.LittleEndian.Uint32(responseHeader[6:10])
+	payloadData := make([]byte, payloadLength)
+
+	responseType := binary.LittleEndian.Uint32(responseHeader[10:14])
+
+	if _, err := io.ReadFull(conn, payloadData); err != nil {
+		return 0, nil, fmt.Errorf("error reading payload data: %w", err)
+	}
+
+	return responseType, payloadData, nil
+}
+
+// one connection for all requests, redialed when it breaks
+var ctl struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// request sends a message on the command connection and returns the reply
+// payload, retrying once on a fresh connection if the old one went stale
+func request(messageType uint32, payload []byte) ([]byte, error) {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if ctl.conn == nil {
+			if ctl.conn, err = connectToI3(); err != nil {
+				return nil, err
+			}
+		}
+
+		var data []byte
+		if err = sendI3Message(ctl.conn, messageType, payload); err == nil {
+			var t uint32
+			t, data, err = readResponse(ctl.conn)
+			if err == nil && t != messageType {
+				err = fmt.Errorf("unexpected reply type %d to %d", t, messageType)
+			}
+		}
+		if err == nil {
+			return data, nil
+		}
+
+		ctl.conn.Close()
+		ctl.conn = nil
+	}
+	return nil, err
+}
+
+func query(messageType uint32, v any) error {
+	data, err := request(messageType, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error unmarshaling reply: %w", err)
+	}
+	return nil
+}
+
+// RunCommand runs an i3 command, like "workspace 3".
+func RunCommand(cmd string) error {
+	data, err := request(msgTypeRunCommand, []byte(cmd))
+	if err != nil {
+		return err
+	}
+
+	var results []struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("error unmarshaling reply: %w", err)
+	}
+	for _, r := range results {
+		if !r.Success {
+			return fmt.Errorf("%s: %s", cmd, r.Error)
+		}
+	}
+	return nil
+}
+
+// BindingState returns the name of the current binding mode, "default"
+// when none is active.
+func BindingState() (string, error) {
+	var state struct {
+		Name string `json:"name"`
+	}
+	return state.Name, query(msgTypeGetBindingState, &state)
+}
+
+// Quote makes s a single argument in an i3 command
+func Quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
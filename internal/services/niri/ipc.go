@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package niri
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// niri speaks json lines on $NIRI_SOCKET: one request, one reply of
+// {"Ok": ...} or {"Err": "..."}. see niri-ipc for the types.
+
+type reply struct {
+	Ok  json.RawMessage `json:"Ok"`
+	Err *string         `json:"Err"`
+}
+
+func connect() (net.Conn, error) {
+	sock := os.Getenv("NIRI_SOCKET")
+	if sock == "" {
+		return nil, fmt.Errorf("NIRI_SOCKET is not set")
+	}
+	return net.Dial("unix", sock)
+}
+
+// send writes req and decodes the reply on dec, returning the Ok payload
+func send(conn net.Conn, dec *json.Decoder, req any) (json.RawMessage, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	var r reply
+	if err := dec.Decode(&r); err != nil {
+		return nil, fmt.Errorf("reading reply to %s: %w", data, err)
+	}
+	if r.Err != nil {
+		return nil, fmt.Errorf("%s: %s", data, *r.Err)
+	}
+	return r.Ok, nil
+}
+
+// Request sends req (like "Workspaces" or {"Action": ...}) on a new
+// connection and returns the Ok payload.
+func Request(req any) (json.RawMessage, error) {
+	conn, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return send(conn, json.NewDecoder(conn), req)
+}
+
+// query sends a request without arguments and decodes the reply, which niri
+// wraps in an object keyed by the request name
+func query(name string, v any) error {
+	ok, err := Request(name)
+	if err != nil {
+		return err
+	}
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(ok, &wrapped); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := json.Unmarshal(wrapped[name], v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+type Workspace struct {
+	Id             uint64  `json:"id"`
+	Idx            int     `json:"idx"` // 1-based, per output
+	Name           string  `json:"name"`
+	Output         string  `json:"output"`
+	IsUrgent       bool    `json:"is_urgent"`
+	IsActive       bool    `json:"is_active"` // shown on its output
+	IsFocused      bool    `json:"is_focused"`
+	ActiveWindowId *uint64 `json:"active_window_id"`
+}
+
+type Window struct {
+	Id          uint64 `json:"id"`
+	Title       string `json:"title"`
+	AppId       string `json:"app_id"`
+	Pid         int    `json:"pid"`
+	WorkspaceId uint64 `json:"workspace_id"`
+	IsFocused   bool   `json:"is_focused"`
+	IsFloating  bool   `json:"is_floating"`
+	IsUrgent    bool   `json:"is_urgent"`
+}
+
+type Mode struct {
+	Width       int  `json:"width"`
+	Height      int  `json:"height"`
+	RefreshRate int  `json:"refresh_rate"` // mHz
+	IsPreferred bool `json:"is_preferred"`
+}
+
+type Logical struct {
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Scale  float64 `json:"scale"`
+}
+
+type Output struct {
+	Name        string   `json:"name"`
+	Make        string   `json:"make"`
+	Model       string   `json:"model"`
+	Modes       []Mode   `json:"modes"`
+	CurrentMode *int     `json:"current_mode"`
+	Logical     *Logical `json:"logical"` // nil when disabled
+}
+
+func Workspaces() ([]Workspace, error) {
+	var o []Workspace
+	return o, query("Workspaces", &o)
+}
+
+func Windows() ([]Window, error) {
+	var o []Window
+	return o, query("Windows", &o)
+}
+
+// FocusedWindow returns nil when nothing is focused.
+func FocusedWindow() (*Window, error) {
+	var o *Window
+	return o, query("FocusedWindow", &o)
+}
+
+func Outputs() (map[string]Output, error) {
+	var o map[string]Output
+	return o, query("Outputs", &o)
+}
+
+// FocusedOutput returns nil when there is no output.
+func FocusedOutput() (*Output, error) {
+	var o *Output
+	return o, query("FocusedOutput", &o)
+}
+
+// Action runs a niri action, like Action("FocusWorkspace", map[string]any{...}).
+func Action(name string, args map[string]any) error {
+	if args == nil {
+		args = map[string]any{}
+	}
+	_, err := Request(map[string]any{"Action": map[string]any{name: args}})
+	return err
+}
+
+// WorkspaceRef picks a workspace for actions: by id, by index on the
+// focused output or by name.
+type WorkspaceRef map[string]any
+
+func RefId(id uint64) WorkspaceRef     { return WorkspaceRef{"Id": id} }
+func RefIndex(idx int) WorkspaceRef    { return WorkspaceRef{"Index": idx} }
+func RefName(name string) WorkspaceRef { return WorkspaceRef{"Name": name} }
+
+// ParseRef reads "id:3" as an id, plain numbers as an index and anything
+// else as a name.
+func ParseRef(s string) WorkspaceRef {
+	if id, ok := strings.CutPrefix(s, "id:"); ok {
+		if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+			return RefId(n)
+		}
+	}
+	if idx, err := strconv.Atoi(s); err == nil {
+		return RefIndex(idx)
+	}
+	return RefName(s)
+}
+
+func FocusWorkspace(ref WorkspaceRef) error {
+	return Action("FocusWorkspace", map[string]any{"reference": ref})
+}
@@ -0,0 +1,430 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package niri
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nekorg/pawbar/internal/services"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+func Register() (*Service, bool) {
+	if s, ok := services.Ensure("niri", func() services.Service { return &Service{} }).(*Service); ok {
+		return s, true
+	}
+	return nil, false
+}
+
+// events that can be passed to RegisterChannel, named like niri's own
+const (
+	EventWorkspacesChanged            = "WorkspacesChanged"
+	EventWorkspaceUrgencyChanged      = "WorkspaceUrgencyChanged"
+	EventWorkspaceActivated           = "WorkspaceActivated"
+	EventWorkspaceActiveWindowChanged = "WorkspaceActiveWindowChanged"
+	EventWindowsChanged               = "WindowsChanged"
+	EventWindowOpenedOrChanged        = "WindowOpenedOrChanged"
+	EventWindowClosed                 = "WindowClosed"
+	EventWindowFocusChanged           = "WindowFocusChanged"
+	EventWindowUrgencyChanged         = "WindowUrgencyChanged"
+	EventKeyboardLayoutsChanged       = "KeyboardLayoutsChanged"
+	EventKeyboardLayoutSwitched       = "KeyboardLayoutSwitched"
+)
+
+const (
+	minBackoff = 250 * time.Millisecond
+	maxBackoff = 10 * time.Second
+)
+
+// Event is sent after the service applied it to its state, read the state
+// back with Workspaces, Windows and FocusedWindow.
+type Event struct {
+	Name    string
+	Payload json.RawMessage
+}
+
+// ResyncEvent is sent to every channel after the event stream came back or
+// events were dropped for a slow consumer
+type ResyncEvent struct{}
+
+// ErrorEvent is sent to every channel when the event stream is lost, the
+// service keeps trying to reconnect
+type ErrorEvent struct{ Err error }
+
+// the event stream starts with the full state, then keeps it up to date.
+// keeping it here saves every module from doing the same.
+type state struct {
+	workspaces map[uint64]Workspace
+	windows    map[uint64]Window
+	focused    *uint64 // focused window
+}
+
+type Service struct {
+	mu      sync.Mutex
+	subs    []*services.Subscriber[interface{}]
+	running bool
+	stop    chan struct{}
+	conn    net.Conn
+
+	smu   sync.RWMutex
+	state state
+}
+
+func (n *Service) Name() string { return "niri" }
+
+func (n *Service) Start() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.running {
+		return nil
+	}
+
+	if os.Getenv("NIRI_SOCKET") == "" {
+		return fmt.Errorf("niri is not running.")
+	}
+
+	// fill the state before anyone asks, the stream catches up from here
+	n.load()
+
+	n.stop = make(chan struct{})
+	for _, sub := range n.subs {
+		go sub.Forward(n.stop)
+	}
+	go n.run(n.stop)
+	n.running = true
+	return nil
+}
+
+func (n *Service) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.running {
+		return nil
+	}
+
+	close(n.stop)
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.running = false
+	return nil
+}
+
+// RegisterChannel sends event (one of the Event* constants) to ch as an
+// Event, along with ResyncEvent and ErrorEvent. a channel can be registered
+// for several events, it gets them in order.
+func (n *Service) RegisterChannel(event string, ch chan<- interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, sub := range n.subs {
+		if sub.Out == ch {
+			sub.Events[event] = true
+			return
+		}
+	}
+
+	sub := services.NewSubscriber[interface{}](ch, ResyncEvent{})
+	sub.Events[event] = true
+	n.subs = append(n.subs, sub)
+	if n.stop != nil {
+		go sub.Forward(n.stop)
+	}
+}
+
+func (n *Service) dispatch(name string, e interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, sub := range n.subs {
+		if name == "" || sub.Events[name] {
+			sub.Push(e)
+		}
+	}
+}
+
+// run keeps the event stream up, retrying with backoff, until the service
+// is stopped
+func (n *Service) run(stop chan struct{}) {
+	backoff := minBackoff
+	connected := false
+	for {
+		conn, err := connect()
+		if err != nil {
+			if connected {
+				utils.Logger.Printf("niri: %v, retrying in %v\n", err, backoff)
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+
+		n.mu.Lock()
+		select {
+		case <-stop:
+			n.mu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		n.conn = conn
+		n.mu.Unlock()
+
+		dec := json.NewDecoder(conn)
+		if _, err = send(conn, dec, "EventStream"); err == nil {
+			if connected {
+				utils.Logger.Println("niri: reconnected")
+				n.dispatch("", ResyncEvent{})
+			}
+			connected = true
+			backoff = minBackoff
+			err = n.read(dec)
+		}
+		conn.Close()
+
+		n.mu.Lock()
+		n.conn = nil
+		n.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		utils.Logger.Printf("niri: event stream lost: %v\n", err)
+		n.dispatch("", ErrorEvent{err})
+	}
+}
+
+func (n *Service) read(dec *json.Decoder) error {
+	for {
+		// every event is an object with the event name as its only key
+		var raw map[string]json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		for name, payload := range raw {
+			if err := n.apply(name, payload); err != nil {
+				utils.Logger.Printf("niri: %s: %v\n", name, err)
+				continue
+			}
+			n.dispatch(name, Event{name, payload})
+		}
+	}
+}
+
+// load queries the state directly, used until the stream delivers it
+func (n *Service) load() {
+	workspaces, err := Workspaces()
+	if err != nil {
+		utils.Logger.Println("niri:", err)
+		return
+	}
+	windows, err := Windows()
+	if err != nil {
+		utils.Logger.Println("niri:", err)
+		return
+	}
+
+	n.smu.Lock()
+	defer n.smu.Unlock()
+	n.setWorkspaces(workspaces)
+	n.setWindows(windows)
+}
+
+func (n *Service) setWorkspaces(workspaces []Workspace) {
+	n.state.workspaces = make(map[uint64]Workspace, len(workspaces))
+	for _, w := range workspaces {
+		n.state.workspaces[w.Id] = w
+	}
+}
+
+func (n *Service) setWindows(windows []Window) {
+	n.state.windows = make(map[uint64]Window, len(windows))
+	n.state.focused = nil
+	for _, w := range windows {
+		n.state.windows[w.Id] = w
+		if w.IsFocused {
+			id := w.Id
+			n.state.focused = &id
+		}
+	}
+}
+
+func (n *Service) focus(id *uint64) {
+	n.state.focused = id
+	for wid, w := range n.state.windows {
+		w.IsFocused = id != nil && wid == *id
+		n.state.windows[wid] = w
+	}
+}
+
+func (n *Service) apply(name string, payload json.RawMessage) error {
+	n.smu.Lock()
+	defer n.smu.Unlock()
+
+	switch name {
+	case EventWorkspacesChanged:
+		var e struct{ Workspaces []Workspace }
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		n.setWorkspaces(e.Workspaces)
+
+	case EventWorkspaceUrgencyChanged:
+		var e struct {
+			Id     uint64
+			Urgent bool
+		}
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		if w, ok := n.state.workspaces[e.Id]; ok {
+			w.IsUrgent = e.Urgent
+			n.state.workspaces[e.Id] = w
+		}
+
+	case EventWorkspaceActivated:
+		var e struct {
+			Id      uint64
+			Focused bool
+		}
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		activated, ok := n.state.workspaces[e.Id]
+		if !ok {
+			return nil
+		}
+		// one active workspace per output, one focused overall
+		for id, w := range n.state.workspaces {
+			if w.Output == activated.Output {
+				w.IsActive = id == e.Id
+			}
+			if e.Focused {
+				w.IsFocused = id == e.Id
+			}
+			n.state.workspaces[id] = w
+		}
+
+	case EventWorkspaceActiveWindowChanged:
+		var e struct {
+			WorkspaceId    uint64  `json:"workspace_id"`
+			ActiveWindowId *uint64 `json:"active_window_id"`
+		}
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		if w, ok := n.state.workspaces[e.WorkspaceId]; ok {
+			w.ActiveWindowId = e.ActiveWindowId
+			n.state.workspaces[e.WorkspaceId] = w
+		}
+
+	case EventWindowsChanged:
+		var e struct{ Windows []Window }
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		n.setWindows(e.Windows)
+
+	case EventWindowOpenedOrChanged:
+		var e struct{ Window Window }
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		if n.state.windows == nil {
+			n.state.windows = make(map[uint64]Window)
+		}
+		n.state.windows[e.Window.Id] = e.Window
+		if e.Window.IsFocused {
+			id := e.Window.Id
+			n.focus(&id)
+		}
+
+	case EventWindowClosed:
+		var e struct{ Id uint64 }
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		delete(n.state.windows, e.Id)
+		if n.state.focused != nil && *n.state.focused == e.Id {
+			n.state.focused = nil
+		}
+
+	case EventWindowFocusChanged:
+		var e struct{ Id *uint64 }
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		n.focus(e.Id)
+
+	case EventWindowUrgencyChanged:
+		var e struct {
+			Id     uint64
+			Urgent bool
+		}
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		if w, ok := n.state.windows[e.Id]; ok {
+			w.IsUrgent = e.Urgent
+			n.state.windows[e.Id] = w
+		}
+	}
+	return nil
+}
+
+// Workspaces returns the known workspaces, by output and then index.
+func (n *Service) Workspaces() []Workspace {
+	n.smu.RLock()
+	defer n.smu.RUnlock()
+
+	ws := make([]Workspace, 0, len(n.state.workspaces))
+	for _, w := range n.state.workspaces {
+		ws = append(ws, w)
+	}
+	sort.Slice(ws, func(a, b int) bool {
+		if ws[a].Output != ws[b].Output {
+			return ws[a].Output < ws[b].Output
+		}
+		return ws[a].Idx < ws[b].Idx
+	})
+	return ws
+}
+
+// Windows returns the known windows, in no particular order.
+func (n *Service) Windows() []Window {
+	n.smu.RLock()
+	defer n.smu.RUnlock()
+
+	wins := make([]Window, 0, len(n.state.windows))
+	for _, w := range n.state.windows {
+		wins = append(wins, w)
+	}
+	return wins
+}
+
+// FocusedWindow returns the focused window, if there is one.
+func (n *Service) FocusedWindow() (Window, bool) {
+	n.smu.RLock()
+	defer n.smu.RUnlock()
+
+	if n.state.focused == nil {
+		return Window{}, false
+	}
+	w, ok := n.state.windows[*n.state.focused]
+	return w, ok
+}
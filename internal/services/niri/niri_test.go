@@ -0,0 +1,302 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package niri
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+// fakeNiri serves $NIRI_SOCKET like niri does: one json request per line,
+// one reply per request, and an event stream after "EventStream"
+type fakeNiri struct {
+	replies map[string]string // request line to reply line
+	reqs    chan string
+	streams chan net.Conn
+}
+
+func serve(t *testing.T) *fakeNiri {
+	t.Helper()
+	if utils.Logger == nil {
+		utils.Logger = log.New(io.Discard, "", 0)
+	}
+
+	sock := filepath.Join(t.TempDir(), "niri.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	t.Setenv("NIRI_SOCKET", sock)
+
+	f := &fakeNiri{
+		replies: map[string]string{
+			`"Workspaces"`: `{"Ok":{"Workspaces":[]}}`,
+			`"Windows"`:    `{"Ok":{"Windows":[]}}`,
+		},
+		reqs:    make(chan string, 16),
+		streams: make(chan net.Conn, 4),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNiri) handle(conn net.Conn) {
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		req := sc.Text()
+		f.reqs <- req
+		switch {
+		case req == `"EventStream"`:
+			io.WriteString(conn, `{"Ok":"Handled"}`+"\n")
+			// the test writes events from here on
+			f.streams <- conn
+			return
+		case strings.HasPrefix(req, `{"Action":`):
+			io.WriteString(conn, `{"Ok":"Handled"}`+"\n")
+		case f.replies[req] != "":
+			io.WriteString(conn, f.replies[req]+"\n")
+		default:
+			io.WriteString(conn, `{"Err":"unknown request"}`+"\n")
+		}
+	}
+	conn.Close()
+}
+
+func (f *fakeNiri) request(t *testing.T) string {
+	t.Helper()
+	select {
+	case r := <-f.reqs:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("no request")
+		return ""
+	}
+}
+
+func (f *fakeNiri) stream(t *testing.T) net.Conn {
+	t.Helper()
+	select {
+	case c := <-f.streams:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event stream")
+		return nil
+	}
+}
+
+func receive(t *testing.T, ch <-chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+		return nil
+	}
+}
+
+func TestRequest(t *testing.T) {
+	f := serve(t)
+	f.replies[`"Workspaces"`] = `{"Ok":{"Workspaces":[{"id":1,"idx":1,"name":"web","output":"DP-1","is_active":true}]}}`
+
+	ws, err := Workspaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 1 || ws[0].Id != 1 || ws[0].Name != "web" || !ws[0].IsActive {
+		t.Errorf("Workspaces() = %+v", ws)
+	}
+	if r := f.request(t); r != `"Workspaces"` {
+		t.Errorf("request = %s", r)
+	}
+
+	_, err = Request("Bogus")
+	if err == nil || !strings.Contains(err.Error(), "unknown request") {
+		t.Errorf("Request(Bogus) error = %v", err)
+	}
+}
+
+func TestFocusWorkspace(t *testing.T) {
+	f := serve(t)
+
+	for ref, want := range map[string]string{
+		"id:3": `{"Action":{"FocusWorkspace":{"reference":{"Id":3}}}}`,
+		"2":    `{"Action":{"FocusWorkspace":{"reference":{"Index":2}}}}`,
+		"web":  `{"Action":{"FocusWorkspace":{"reference":{"Name":"web"}}}}`,
+	} {
+		if err := FocusWorkspace(ParseRef(ref)); err != nil {
+			t.Fatal(err)
+		}
+		if r := f.request(t); r != want {
+			t.Errorf("FocusWorkspace(%q) sent %s, want %s", ref, r, want)
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	f := serve(t)
+
+	n := &Service{}
+	ch := make(chan interface{}, 8)
+	n.RegisterChannel(EventWorkspacesChanged, ch)
+	if err := n.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	// load asks for the state first, then the stream is requested
+	for _, want := range []string{`"Workspaces"`, `"Windows"`, `"EventStream"`} {
+		if r := f.request(t); r != want {
+			t.Fatalf("request = %s, want %s", r, want)
+		}
+	}
+
+	conn := f.stream(t)
+	io.WriteString(conn, `{"WorkspacesChanged":{"workspaces":[{"id":5,"idx":1,"output":"DP-1","is_active":true}]}}`+"\n")
+	// not registered, must not reach ch
+	io.WriteString(conn, `{"WindowClosed":{"id":1}}`+"\n")
+
+	e, ok := receive(t, ch).(Event)
+	if !ok || e.Name != EventWorkspacesChanged {
+		t.Fatalf("got %#v", e)
+	}
+	if ws := n.Workspaces(); len(ws) != 1 || ws[0].Id != 5 {
+		t.Errorf("Workspaces() = %+v", ws)
+	}
+	select {
+	case e := <-ch:
+		t.Errorf("unexpected %#v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestApply(t *testing.T) {
+	n := &Service{}
+	apply := func(name, payload string) {
+		t.Helper()
+		if err := n.apply(name, json.RawMessage(payload)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	workspace := func(id uint64) Workspace {
+		t.Helper()
+		for _, w := range n.Workspaces() {
+			if w.Id == id {
+				return w
+			}
+		}
+		t.Fatalf("no workspace %d", id)
+		return Workspace{}
+	}
+	check := func(id uint64, active, focused bool) {
+		t.Helper()
+		if w := workspace(id); w.IsActive != active || w.IsFocused != focused {
+			t.Errorf("workspace %d: active %v focused %v, want %v %v", id, w.IsActive, w.IsFocused, active, focused)
+		}
+	}
+	focused := func(want uint64) {
+		t.Helper()
+		w, ok := n.FocusedWindow()
+		if want == 0 && ok {
+			t.Errorf("focused window %d, want none", w.Id)
+		} else if want != 0 && (!ok || w.Id != want) {
+			t.Errorf("focused window %d (%v), want %d", w.Id, ok, want)
+		}
+		for _, w := range n.Windows() {
+			if w.IsFocused != (w.Id == want) {
+				t.Errorf("window %d: is_focused %v", w.Id, w.IsFocused)
+			}
+		}
+	}
+
+	apply(EventWorkspacesChanged, `{"workspaces":[
+		{"id":1,"idx":1,"output":"DP-1","is_active":true,"is_focused":true},
+		{"id":2,"idx":2,"output":"DP-1"},
+		{"id":3,"idx":1,"output":"HDMI-A-1","is_active":true},
+		{"id":4,"idx":2,"output":"HDMI-A-1"}]}`)
+	if ws := n.Workspaces(); len(ws) != 4 || ws[0].Id != 1 || ws[2].Id != 3 {
+		t.Fatalf("Workspaces() = %+v", ws)
+	}
+
+	// focusing the other output leaves DP-1's workspace active
+	apply(EventWorkspaceActivated, `{"id":4,"focused":true}`)
+	check(1, true, false)
+	check(2, false, false)
+	check(3, false, false)
+	check(4, true, true)
+
+	// switching on the unfocused output keeps focus where it is
+	apply(EventWorkspaceActivated, `{"id":2,"focused":false}`)
+	check(1, false, false)
+	check(2, true, false)
+	check(4, true, true)
+
+	apply(EventWindowOpenedOrChanged, `{"window":{"id":10,"title":"a","workspace_id":4,"is_focused":true}}`)
+	focused(10)
+	apply(EventWindowOpenedOrChanged, `{"window":{"id":11,"title":"b","workspace_id":2}}`)
+	focused(10)
+	apply(EventWindowOpenedOrChanged, `{"window":{"id":10,"title":"c","workspace_id":4,"is_focused":true}}`)
+	if w, _ := n.FocusedWindow(); w.Title != "c" {
+		t.Errorf("title %q, want c", w.Title)
+	}
+
+	apply(EventWindowFocusChanged, `{"id":null}`)
+	focused(0)
+	apply(EventWindowFocusChanged, `{"id":11}`)
+	focused(11)
+
+	apply(EventWindowClosed, `{"id":11}`)
+	focused(0)
+	if wins := n.Windows(); len(wins) != 1 || wins[0].Id != 10 {
+		t.Errorf("Windows() = %+v", wins)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	f := serve(t)
+
+	n := &Service{}
+	ch := make(chan interface{}, 8)
+	n.RegisterChannel(EventWindowClosed, ch)
+	if err := n.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Stop()
+
+	f.stream(t).Close()
+	if _, ok := receive(t, ch).(ErrorEvent); !ok {
+		t.Fatal("no ErrorEvent after losing the stream")
+	}
+
+	conn := f.stream(t)
+	if _, ok := receive(t, ch).(ResyncEvent); !ok {
+		t.Fatal("no ResyncEvent after reconnecting")
+	}
+
+	io.WriteString(conn, `{"WindowClosed":{"id":1}}`+"\n")
+	if e, ok := receive(t, ch).(Event); !ok || e.Name != EventWindowClosed {
+		t.Fatalf("got %#v after reconnecting", e)
+	}
+}
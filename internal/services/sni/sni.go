@@ -8,6 +8,7 @@ package sni
 
 import (
 	"fmt"
+	"image"
 	"os"
 	"sort"
 	"strings"
@@ -66,6 +67,73 @@ type Item struct {
 	Attention    string
 	MenuPath     dbus.ObjectPath
 	IconThemeDir string
+
+	IconPixmap      []Pixmap
+	OverlayPixmap   []Pixmap
+	AttentionPixmap []Pixmap
+
+	AttentionMovie string // AttentionMovieName, a name or a path
+	ToolTip        ToolTip
+	// the item only has a menu, activating it should show the menu
+	ItemIsMenu bool
+	WindowId   uint32
+}
+
+type ToolTip struct {
+	IconName   string
+	IconPixmap []Pixmap
+	Title      string
+	Body       string // may contain a subset of html
+}
+
+// Pixmap is one size of an icon sent over the bus, Data is ARGB32 in
+// network byte order, row by row
+type Pixmap struct {
+	Width  int32
+	Height int32
+	Data   []byte
+}
+
+// icons bigger than this are bogus, and would cost a lot to decode
+const maxPixmapSize = 1024
+
+func (p Pixmap) valid() bool {
+	if p.Width <= 0 || p.Height <= 0 || p.Width > maxPixmapSize || p.Height > maxPixmapSize {
+		return false
+	}
+	return len(p.Data) >= int(p.Width)*int(p.Height)*4
+}
+
+// Image converts the ARGB32 data to an image, nil if the pixmap is malformed
+func (p Pixmap) Image() image.Image {
+	if !p.valid() {
+		return nil
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, int(p.Width), int(p.Height)))
+	for i := 0; i < int(p.Width)*int(p.Height); i++ {
+		a, r, g, b := p.Data[i*4], p.Data[i*4+1], p.Data[i*4+2], p.Data[i*4+3]
+		img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2], img.Pix[i*4+3] = r, g, b, a
+	}
+	return img
+}
+
+// PixmapImage picks the largest valid pixmap and converts it, nil if there
+// is none
+func PixmapImage(pix []Pixmap) image.Image {
+	var best *Pixmap
+	for i := range pix {
+		p := &pix[i]
+		if !p.valid() {
+			continue
+		}
+		if best == nil || int(p.Width)*int(p.Height) > int(best.Width)*int(best.Height) {
+			best = p
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.Image()
 }
 
 type Service struct {
@@ -896,39 +964,46 @@ func (s *Service) loop() {
 	}
 }
 
-func (s *Service) refreshItem(it *Item) {
-	obj := s.conn.Object(it.BusName, it.Path)
+// every property we read, see setProps
+var itemProps = []string{
+	"Id", "Title", "Status", "Category", "Menu", "ItemIsMenu", "WindowId",
+	"IconName", "IconPixmap", "OverlayIconName", "OverlayIconPixmap",
+	"AttentionIconName", "AttentionIconPixmap", "AttentionMovieName",
+	"IconThemePath", "ToolTip",
+}
+
+// the New* signals only say something changed, these are what to re-read
+var signalProps = map[string][]string{
+	ifaceItem + ".NewTitle":         {"Title"},
+	ifaceItem + ".NewIcon":          {"IconName", "IconPixmap"},
+	ifaceItem + ".NewAttentionIcon": {"AttentionIconName", "AttentionIconPixmap", "AttentionMovieName"},
+	ifaceItem + ".NewOverlayIcon":   {"OverlayIconName", "OverlayIconPixmap"},
+	ifaceItem + ".NewToolTip":       {"ToolTip"},
+	ifaceItem + ".NewStatus":        {"Status"},
+	ifaceItem + ".NewIconThemePath": {"IconThemePath"},
+	ifaceItem + ".NewMenu":          {"Menu"},
+}
 
-	grab := func(p string, into *string) {
+// fetchProps reads props one by one, GetAll fails as a whole when an item
+// doesn't implement one of them
+func (s *Service) fetchProps(it *Item, props ...string) map[string]dbus.Variant {
+	obj := s.conn.Object(it.BusName, it.Path)
+	out := make(map[string]dbus.Variant, len(props))
+	for _, p := range props {
 		var v dbus.Variant
 		if err := obj.Call("org.freedesktop.DBus.Properties.Get", 0, ifaceItem, p).Store(&v); err == nil {
-			if str, ok := v.Value().(string); ok {
-				*into = str
-			}
-		}
-	}
-
-	var mp dbus.Variant
-	if err := obj.Call("org.freedesktop.DBus.Properties.Get", 0, ifaceItem, "Menu").Store(&mp); err == nil {
-		if p, ok := mp.Value().(dbus.ObjectPath); ok {
-			it.MenuPath = p
+			out[p] = v
 		}
 	}
+	return out
+}
 
-	grab("Id", &it.Id)
-	grab("Title", &it.Title)
-	grab("Status", &it.Status)
-	grab("IconName", &it.IconName)
-	grab("OverlayIconName", &it.OverlayName)
-	grab("AttentionIconName", &it.Attention)
-	grab("IconThemePath", &it.IconThemeDir)
+func (s *Service) refreshItem(it *Item) {
+	props := s.fetchProps(it, itemProps...)
 
-	var catv dbus.Variant
-	if err := obj.Call("org.freedesktop.DBus.Properties.Get", 0, ifaceItem, "Category").Store(&catv); err == nil {
-		if c, ok := catv.Value().(string); ok {
-			it.Category = Category(c)
-		}
-	}
+	s.mu.Lock()
+	setProps(it, props)
+	s.mu.Unlock()
 }
 
 func (s *Service) watchItemProps(it *Item) {
@@ -936,6 +1011,16 @@ func (s *Service) watchItemProps(it *Item) {
 		it.Path, it.BusName)
 	s.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule)
 
+	// most items never send PropertiesChanged, only the New* signals
+	itemRule := fmt.Sprintf("type='signal',interface='%s',path='%s',sender='%s'", ifaceItem, it.Path, it.BusName)
+	s.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, itemRule)
+
+	// signals carry the unique name of the sender
+	owner := it.BusName
+	if !strings.HasPrefix(owner, ":") {
+		s.conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, it.BusName).Store(&owner)
+	}
+
 	ch := make(chan *dbus.Signal, 16)
 	s.conn.Signal(ch)
 
@@ -944,16 +1029,21 @@ func (s *Service) watchItemProps(it *Item) {
 		case <-s.stop:
 			return
 		case sig := <-ch:
-			if sig == nil || sig.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+			if sig == nil {
 				continue
 			}
 
 			// Verify it's for our item
-			if sig.Path != it.Path || sig.Sender != it.BusName {
+			if sig.Path != it.Path || (sig.Sender != it.BusName && sig.Sender != owner) {
+				continue
+			}
+
+			if props, ok := signalProps[sig.Name]; ok {
+				s.applyChanges(it, s.fetchProps(it, props...))
 				continue
 			}
 
-			if len(sig.Body) < 2 {
+			if sig.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" || len(sig.Body) < 2 {
 				continue
 			}
 
@@ -1065,51 +1155,81 @@ func (s *Service) itemStillExists(it *Item) bool {
 
 func (s *Service) applyChanges(it *Item, changed map[string]dbus.Variant) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	setProps(it, changed)
+	ev := Event{Kind: ItemChanged, ID: it.BusName + string(it.Path), Item: *it}
+	s.mu.Unlock()
+
+	s.emit(ev)
+}
+
+// setProps decodes item properties into it, values of the wrong type are
+// ignored. callers hold s.mu
+func setProps(it *Item, props map[string]dbus.Variant) {
+	str := func(v dbus.Variant, into *string) {
+		if x, ok := v.Value().(string); ok {
+			*into = x
+		}
+	}
+	pix := func(v dbus.Variant, into *[]Pixmap) {
+		var px []Pixmap
+		if v.Store(&px) == nil {
+			*into = px
+		}
+	}
 
-	for k, v := range changed {
+	for k, v := range props {
 		switch k {
 		case "Id":
-			if str, ok := v.Value().(string); ok {
-				it.Id = str
-			}
+			str(v, &it.Id)
 		case "Title":
-			if str, ok := v.Value().(string); ok {
-				it.Title = str
-			}
+			str(v, &it.Title)
 		case "Status":
-			if str, ok := v.Value().(string); ok {
-				it.Status = str
-			}
+			str(v, &it.Status)
 		case "IconName":
-			if str, ok := v.Value().(string); ok {
-				it.IconName = str
-			}
+			str(v, &it.IconName)
 		case "OverlayIconName":
-			if str, ok := v.Value().(string); ok {
-				it.OverlayName = str
-			}
+			str(v, &it.OverlayName)
 		case "AttentionIconName":
-			if str, ok := v.Value().(string); ok {
-				it.Attention = str
-			}
+			str(v, &it.Attention)
+		case "AttentionMovieName":
+			str(v, &it.AttentionMovie)
 		case "IconThemePath":
-			if str, ok := v.Value().(string); ok {
-				it.IconThemeDir = str
+			str(v, &it.IconThemeDir)
+		case "IconPixmap":
+			pix(v, &it.IconPixmap)
+		case "OverlayIconPixmap":
+			pix(v, &it.OverlayPixmap)
+		case "AttentionIconPixmap":
+			pix(v, &it.AttentionPixmap)
+		case "ToolTip":
+			var tt ToolTip
+			if v.Store(&tt) == nil {
+				it.ToolTip = tt
+			}
+		case "ItemIsMenu":
+			if b, ok := v.Value().(bool); ok {
+				it.ItemIsMenu = b
+			}
+		case "WindowId":
+			// spec says uint32, KDE sends int32
+			switch id := v.Value().(type) {
+			case uint32:
+				it.WindowId = id
+			case int32:
+				it.WindowId = uint32(id)
 			}
 		case "Menu":
 			if p, ok := v.Value().(dbus.ObjectPath); ok {
 				it.MenuPath = p
 			}
 		case "Category":
-			if str, ok := v.Value().(string); ok {
-				it.Category = Category(str)
+			var c string
+			str(v, &c)
+			if c != "" {
+				it.Category = Category(c)
 			}
 		}
 	}
-
-	key := it.BusName + string(it.Path)
-	s.emit(Event{Kind: ItemChanged, ID: key, Item: *it})
 }
 
 func (s *Service) emit(ev Event) {
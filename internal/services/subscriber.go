@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package services
+
+import "sync/atomic"
+
+const queueSize = 64
+
+// Subscriber queues events for one channel so a slow consumer doesn't hold
+// up the others. when the queue overflows the dropped events are made up for
+// with a resync event once the consumer catches up.
+type Subscriber[T any] struct {
+	Out    chan<- T
+	Events map[string]bool // names the consumer asked for
+
+	queue  chan T
+	resync T
+	lost   atomic.Bool
+}
+
+func NewSubscriber[T any](out chan<- T, resync T) *Subscriber[T] {
+	return &Subscriber[T]{
+		Out:    out,
+		Events: make(map[string]bool),
+		queue:  make(chan T, queueSize),
+		resync: resync,
+	}
+}
+
+// Push queues e without blocking.
+func (s *Subscriber[T]) Push(e T) {
+	select {
+	case s.queue <- e:
+	default:
+		// full, the consumer has to resync once it catches up
+		s.lost.Store(true)
+	}
+}
+
+// Forward hands queued events to Out until stop is closed, run it once per
+// Start of the service.
+func (s *Subscriber[T]) Forward(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case e := <-s.queue:
+			if !s.send(e, stop) {
+				return
+			}
+			if len(s.queue) == 0 && s.lost.Swap(false) && !s.send(s.resync, stop) {
+				return
+			}
+		}
+	}
+}
+
+// send blocks until the consumer takes e or the service stops, a stopped
+// send counts as lost so the consumer resyncs after a restart
+func (s *Subscriber[T]) send(e T, stop <-chan struct{}) bool {
+	select {
+	case s.Out <- e:
+		return true
+	case <-stop:
+		s.lost.Store(true)
+		return false
+	}
+}
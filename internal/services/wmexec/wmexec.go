@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+// Package wmexec runs a user command that reports window manager state as
+// json lines on stdout and takes requests as json lines on stdin. it backs
+// the ws and title modules on window managers pawbar doesn't know.
+package wmexec
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekorg/pawbar/internal/services"
+	"github.com/nekorg/pawbar/internal/utils"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+	maxLine    = 1 << 20
+)
+
+// Register returns the stream for argv, modules asking for the same
+// command share one copy of it.
+func Register(argv []string) (*Stream, error) {
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("no command given")
+	}
+	if _, err := exec.LookPath(argv[0]); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("wmexec %q", argv)
+	s, ok := services.Ensure(name, func() services.Service { return &Stream{name: name, argv: argv} }).(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("%s is registered as something else", name)
+	}
+	return s, nil
+}
+
+// Stream keeps the command running, restarting it when it exits.
+type Stream struct {
+	name string
+	argv []string
+
+	mu      sync.Mutex
+	running bool
+	stop    chan struct{}
+	stdin   io.WriteCloser
+	subs    []chan []byte
+	last    map[string][]byte // latest line for each set of keys
+}
+
+func (s *Stream) Name() string { return s.name }
+
+func (s *Stream) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return nil
+	}
+
+	s.stop = make(chan struct{})
+	go s.run(s.stop)
+	s.running = true
+	return nil
+}
+
+// Stop kills the command, Start runs it again.
+func (s *Stream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return nil
+	}
+
+	close(s.stop)
+	s.running = false
+	return nil
+}
+
+// Subscribe returns a channel that gets every line read from the command's
+// stdout. it starts with the latest line of each kind, so a module that
+// subscribes late doesn't have to wait for the next change.
+func (s *Stream) Subscribe() <-chan []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan []byte, len(s.last)+16)
+	for _, line := range s.last {
+		ch <- line
+	}
+	s.subs = append(s.subs, ch)
+	return ch
+}
+
+// Send writes v as a json line to the command, it's dropped while the
+// command is restarting.
+func (s *Stream) Send(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stdin == nil {
+		return fmt.Errorf("%s is not running", s.argv[0])
+	}
+	_, err = s.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (s *Stream) run(stop chan struct{}) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := s.runOnce(stop)
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		// a command that ran for a while starts over from the shortest wait
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		utils.Logger.Printf("wmexec: %s exited (%v), restarting in %v\n", s.argv[0], err, backoff)
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (s *Stream) runOnce(stop chan struct{}) error {
+	cmd := exec.Command(s.argv[0], s.argv[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.stdin = stdin
+	s.mu.Unlock()
+
+	// kill it when the service stops, that also ends the scan below
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	for scanner.Scan() {
+		s.publish(append([]byte(nil), scanner.Bytes()...), stop)
+	}
+
+	s.mu.Lock()
+	s.stdin.Close()
+	s.stdin = nil
+	s.mu.Unlock()
+
+	// nobody reads stdout anymore, don't leave the command blocked on it
+	if err := scanner.Err(); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+func (s *Stream) publish(line []byte, stop chan struct{}) {
+	s.mu.Lock()
+	if s.last == nil {
+		s.last = make(map[string][]byte)
+	}
+	if k, ok := kind(line); ok {
+		s.last[k] = line
+	}
+	subs := s.subs
+	s.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- line:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// kind is the sorted top level keys of a json object line
+func kind(line []byte) (string, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return "", false
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ","), true
+}
@@ -82,12 +82,46 @@ func flatten(mods []modules.Module) []modules.EventCell {
 	// ws with 1 workspace requires 3, so most of them will
 	// take more than that right? right? (foreshadowing)
 	out := make([]modules.EventCell, 0, len(mods)*3)
-	for _, m := range mods {
+	for _, m := range visible(mods) {
 		out = append(out, modMap[m]...)
 	}
 	return out
 }
 
+// drops modules that rendered nothing along with one separator next to
+// them, so hidden modules don't leave "| |" behind
+func visible(mods []modules.Module) []modules.Module {
+	if !hideEmpty {
+		return mods
+	}
+
+	kept := make([]modules.Module, 0, len(mods))
+	skipSep := false
+	for _, m := range mods {
+		isSep := m.Name() == "sep"
+		if isSep && skipSep {
+			skipSep = false
+			continue
+		}
+		if len(modMap[m]) > 0 || isSep {
+			kept = append(kept, m)
+			if !isSep {
+				skipSep = false
+			}
+			continue
+		}
+
+		// prefer the separator before, the next one only goes when
+		// nothing is left before it
+		if n := len(kept); n > 0 && kept[n-1].Name() == "sep" {
+			kept = kept[:n-1]
+		} else if n == 0 {
+			skipSep = true
+		}
+	}
+	return kept
+}
+
 // this keeps account for grapheme widths
 // so this is safe for anchor calculations; probably?
 func totalWidth(cells []modules.EventCell) int {
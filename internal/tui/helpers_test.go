@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nekorg/pawbar/internal/modules"
+)
+
+type fakeModule struct{ name string }
+
+func (m *fakeModule) Render() []modules.EventCell                     { return nil }
+func (m *fakeModule) Run() (<-chan bool, chan<- modules.Event, error) { return nil, nil, nil }
+func (m *fakeModule) Channels() (<-chan bool, chan<- modules.Event)   { return nil, nil }
+func (m *fakeModule) Name() string                                    { return m.name }
+func (m *fakeModule) Dependencies() []string                          { return nil }
+
+func TestVisible(t *testing.T) {
+	// "|" is a sep, lowercase modules render nothing
+	tests := []struct {
+		bar, want string
+	}{
+		{"A B C", "A B C"},
+		{"A | b | C", "A | C"},
+		{"A b | C", "A | C"},
+		{"A | b c | D", "A | D"},
+		{"b | C", "C"},
+		{"b c | D", "D"},
+		{"A | b", "A"},
+		{"A | b | c", "A"},
+		{"a | b", ""},
+		{"A | | C", "A | | C"},
+	}
+
+	defer func(old bool) { hideEmpty = old }(hideEmpty)
+	hideEmpty = true
+
+	for _, tt := range tests {
+		modMap = make(map[modules.Module][]modules.EventCell)
+		var mods []modules.Module
+		for _, f := range strings.Fields(tt.bar) {
+			m := &fakeModule{name: f}
+			if f == "|" {
+				m.name = "sep"
+				modMap[m] = []modules.EventCell{modules.ECSPACE}
+			} else if strings.ToUpper(f) == f {
+				modMap[m] = []modules.EventCell{modules.ECSPACE}
+			}
+			mods = append(mods, m)
+		}
+
+		var got []string
+		for _, m := range visible(mods) {
+			name := m.(*fakeModule).name
+			if name == "sep" {
+				name = "|"
+			}
+			got = append(got, name)
+		}
+		if g := strings.Join(got, " "); g != tt.want {
+			t.Errorf("visible(%q) = %q, want %q", tt.bar, g, tt.want)
+		}
+	}
+}
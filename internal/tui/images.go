@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package tui
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"github.com/nekorg/pawbar/internal/modules"
+)
+
+// images uploaded to the terminal, keyed by what modules return
+var kitty = make(map[*modules.Image]*vaxis.KittyImage)
+
+// places every image whose cells all made it to the screen. vaxis diffs
+// placements between frames, so the ones that moved or went away are
+// removed on its own, we only have to free the uploads nobody uses anymore.
+func placeImages(win vaxis.Window) {
+	for x := 0; x < width; {
+		im := state[x].Image
+		if im == nil {
+			x++
+			continue
+		}
+
+		n := 0
+		for x+n < width && state[x+n].Image == im && n < im.Width {
+			n++
+		}
+		if n == im.Width && !state[x].Hidden {
+			k, ok := kitty[im]
+			if !ok {
+				k = win.Vx.NewKittyGraphic(im.Img)
+				k.Resize(im.Width, 1)
+				kitty[im] = k
+			}
+			k.Draw(win.New(x, 0, im.Width, 1))
+		}
+		x += n
+	}
+
+	live := make(map[*modules.Image]bool)
+	for _, cells := range modMap {
+		for _, c := range cells {
+			if c.Image != nil {
+				live[c.Image] = true
+			}
+		}
+	}
+	for im, k := range kitty {
+		if !live[im] {
+			k.Destroy()
+			delete(kitty, im)
+		}
+	}
+}
+
+func dropImages() {
+	for im, k := range kitty {
+		k.Destroy()
+		delete(kitty, im)
+	}
+}
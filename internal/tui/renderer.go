@@ -24,6 +24,7 @@ var (
 	useEllipsis   bool
 	ellipsisCells []modules.EventCell
 	ellipsisWidth int
+	hideEmpty     bool
 )
 
 type anchor int
@@ -56,6 +57,7 @@ func Init(w, h int, l, m, r []modules.Module, barCfg config.BarSettings) {
 	useEllipsis = barCfg.EnableEllipsis == nil || *barCfg.EnableEllipsis
 	ellipsisCells = stringToEC(barCfg.Ellipsis)
 	ellipsisWidth = totalWidth(ellipsisCells)
+	hideEmpty = barCfg.HideEmpty != nil && *barCfg.HideEmpty
 
 	state = make([]modules.EventCell, width+1) // sometimes kitty can report mouse events outside reported width, like at the edge, idk why.
 	refreshModMap(leftModules, middleModules, rightModules)
@@ -64,6 +66,7 @@ func Init(w, h int, l, m, r []modules.Module, barCfg config.BarSettings) {
 func Resize(w, h int) {
 	width = w
 	height = h
+	dropImages() // cell size in pixels may have changed
 
 	state = make([]modules.EventCell, width+1)
 }
@@ -79,6 +82,11 @@ func PartialRender(win vaxis.Window, m modules.Module) {
 }
 
 func render(win vaxis.Window) {
+	layout(win)
+	placeImages(win)
+}
+
+func layout(win vaxis.Window) {
 	for i := range width {
 		state[i] = modules.ECSPACE
 	}
@@ -7,10 +7,11 @@
 package dbusmenukitty
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nekorg/katnip"
@@ -30,6 +31,20 @@ type Layout struct {
 	Children   []Layout
 }
 
+// MarshalJSON unwraps the property variants so the tree reads like what the
+// app sent
+func (l Layout) MarshalJSON() ([]byte, error) {
+	props := make(map[string]any, len(l.Properties))
+	for k, v := range l.Properties {
+		props[k] = v.Value()
+	}
+	return json.Marshal(struct {
+		Id         int32          `json:"id"`
+		Properties map[string]any `json:"properties"`
+		Children   []Layout       `json:"children,omitempty"`
+	}{l.Id, props, l.Children})
+}
+
 type DBusMenuClient struct {
 	conn    *dbus.Conn
 	obj     dbus.BusObject
@@ -96,16 +111,37 @@ func (c *DBusMenuClient) AboutToShow(id int32) (bool, error) {
 	return needUpdate, err
 }
 
-func LaunchMenu(x, y int) {
-	busname := "org.freedesktop.network-manager-applet"
-	path := "/org/ayatana/NotificationItem/nm_applet/Menu"
+// Placement is where a menu opens. X and Y are logical pixels from the top
+// left of Output (empty lets the compositor pick one), Scale is the output's
+// scale, used to turn the menu's own pixel sizes into logical ones for
+// submenus.
+type Placement struct {
+	X, Y   int
+	Output string
+	Scale  float64
+}
+
+func (p Placement) logical(px float64) int {
+	if p.Scale <= 0 {
+		return int(px)
+	}
+	return int(px / p.Scale)
+}
+
+// only one menu is open at a time, opening another closes the current one
+var launchMu sync.Mutex
 
-	// busname = "org.blueman.Tray"
-	// path = "/org/blueman/sni/menu"
+// LaunchMenu opens the dbusmenu exported by busname at path (usually a tray
+// item's BusName and MenuPath) as a popup at p. It blocks until the menu is
+// closed.
+func LaunchMenu(busname, path string, p Placement) error {
+	launchMu.Lock()
+	menu.GetManager().CloseAllMenus()
 
 	client, err := NewDBusMenuClient(busname, path)
 	if err != nil {
-		log.Fatalf("error creating dbus client: %v", err)
+		launchMu.Unlock()
+		return fmt.Errorf("error creating dbus client: %w", err)
 	}
 	defer client.Close()
 
@@ -114,29 +150,46 @@ func LaunchMenu(x, y int) {
 	client.obj.StoreProperty("com.canonical.dbusmenu.Status", &status)
 	utils.Logger.Printf("Status: %s\n", status)
 
+	// apps may build the menu lazily
+	if _, err := client.AboutToShow(0); err != nil {
+		utils.Logger.Printf("error calling AboutToShow: %v", err)
+	}
+
 	// Get initial layout
 	layout, err := client.GetLayout()
 	if err != nil {
-		log.Fatalf("error getting layout: %v", err)
+		launchMu.Unlock()
+		return fmt.Errorf("error getting layout of %s%s: %w", busname, path, err)
 	}
 
 	utils.Logger.Printf("Layout retrieved\n")
 	// printLayout(layout, 0)
 
 	menuItems := FlattenLayout(layout)
+	if len(menuItems) == 0 {
+		launchMu.Unlock()
+		return fmt.Errorf("menu of %s%s is empty", busname, path)
+	}
+
+	// unlocked once the panel is registered, so the next launch can close it
+	createMenuPanel(client, p, menuItems, 0, launchMu.Unlock)
+	return nil
+}
 
-	CreateMenuPanel(client, x, y, menuItems, 0)
+func CreateMenuPanel(client *DBusMenuClient, p Placement, menuItems []menu.Item, parentId int32) {
+	createMenuPanel(client, p, menuItems, parentId, func() {})
 }
 
-func CreateMenuPanel(client *DBusMenuClient, x, y int, menuItems []menu.Item, parentId int32) {
+func createMenuPanel(client *DBusMenuClient, p Placement, menuItems []menu.Item, parentId int32, registered func()) {
 	maxHorizontalLength, maxVerticalLength := menu.MaxLengthLabel(menuItems)+4, len(menuItems)
 	utils.Logger.Printf("%d, %d\n", maxHorizontalLength, maxVerticalLength)
 
-	kn := CreatePanel(x, y, maxHorizontalLength, maxVerticalLength)
+	kn := CreatePanel(p, maxHorizontalLength, maxVerticalLength)
 
 	// Register with submenu manager
 	sm := menu.GetManager()
-	sm.AddPanel(kn, x, y)
+	sm.AddPanel(kn, p.X, p.Y)
+	registered()
 
 	defer func() {
 		sm.HandlePanelExit(kn)
@@ -255,10 +308,12 @@ func CreateMenuPanel(client *DBusMenuClient, x, y int, menuItems []menu.Item, pa
 						submenuItems := FlattenLayout(submenuLayout)
 						if len(submenuItems) > 0 {
 							sm.CloseAllSubmenus()
-							submenuX := x + msg.Payload.X - int((msg.Payload.State.PPC.X*float64(menu.MaxLengthLabel(submenuItems)+4))/2)
-							submenuY := y + int((float64(msg.Payload.Y)*msg.Payload.State.PPC.Y)/2)
+							// opens to the left, level with the row it came from
+							sub := p
+							sub.X = p.X + p.logical(float64(msg.Payload.X)-msg.Payload.State.PPC.X*float64(menu.MaxLengthLabel(submenuItems)+4))
+							sub.Y = p.Y + p.logical(float64(msg.Payload.Y)*msg.Payload.State.PPC.Y)
 							// Launch submenu panel recursively this time instead bruh
-							go CreateMenuPanel(client, submenuX, submenuY, submenuItems, msg.Payload.ItemId)
+							go CreateMenuPanel(client, sub, submenuItems, msg.Payload.ItemId)
 						}
 					}
 				}
@@ -266,47 +321,38 @@ func CreateMenuPanel(client *DBusMenuClient, x, y int, menuItems []menu.Item, pa
 		}
 	}()
 
-	// Listen for DBus signals for layout updates
-	go func() {
-		rule := fmt.Sprintf("type='signal',sender='%s',path='%s',interface='com.canonical.dbusmenu'", client.busname, client.path)
-		client.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule)
+	// Listen for DBus signals for layout updates, the open panel re-lays
+	// itself out (and resizes) on every MsgMenuUpdate
+	rule := fmt.Sprintf("type='signal',sender='%s',path='%s',interface='com.canonical.dbusmenu'", client.busname, client.path)
+	client.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule)
 
-		ch := make(chan *dbus.Signal, 10)
-		client.conn.Signal(ch)
+	ch := make(chan *dbus.Signal, 10)
+	client.conn.Signal(ch)
+	defer func() {
+		client.conn.RemoveSignal(ch)
+		client.conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule)
+		close(ch)
+	}()
 
+	go func() {
 		for signal := range ch {
+			if string(signal.Path) != client.path {
+				continue
+			}
 			switch signal.Name {
-			case "com.canonical.dbusmenu.LayoutUpdated":
-				utils.Logger.Printf("Layout updated signal received for panel %d", parentId)
-				// Refresh layout
+			case "com.canonical.dbusmenu.LayoutUpdated", "com.canonical.dbusmenu.ItemsPropertiesUpdated":
+				utils.Logger.Printf("%s received for panel %d", signal.Name, parentId)
 				newLayout, err := client.GetLayoutForParent(parentId)
 				if err != nil {
 					utils.Logger.Printf("error refreshing layout after signal: %v", err)
-				} else {
-					newMenuItems := FlattenLayout(newLayout)
-					updateMsg := menu.Message{
-						Type: menu.MsgMenuUpdate,
-						Payload: menu.MessagePayload{
-							Menu: newMenuItems,
-						},
-					}
-					enc.Encode(updateMsg)
-				}
-			case "com.canonical.dbusmenu.ItemsPropertiesUpdated":
-				utils.Logger.Printf("Items properties updated signal received for panel %d", parentId)
-				newLayout, err := client.GetLayoutForParent(parentId)
-				if err != nil {
-					utils.Logger.Printf("error refreshing layout after properties update: %v", err)
-				} else {
-					newMenuItems := FlattenLayout(newLayout)
-					updateMsg := menu.Message{
-						Type: menu.MsgMenuUpdate,
-						Payload: menu.MessagePayload{
-							Menu: newMenuItems,
-						},
-					}
-					enc.Encode(updateMsg)
+					continue
 				}
+				enc.Encode(menu.Message{
+					Type: menu.MsgMenuUpdate,
+					Payload: menu.MessagePayload{
+						Menu: FlattenLayout(newLayout),
+					},
+				})
 			}
 		}
 	}()
@@ -390,12 +436,13 @@ func init() {
 	katnip.RegisterFunc("leaf", tui.Leaf)
 }
 
-func CreatePanel(x, y, w, h int) *katnip.Panel {
+func CreatePanel(p Placement, w, h int) *katnip.Panel {
 	conf := katnip.Config{
-		Position: katnip.Vector{X: x, Y: y},
-		Size:     katnip.Vector{X: w, Y: h},
-		Edge:     katnip.EdgeNone,
-		Layer:    katnip.LayerTop,
+		Position:   katnip.Vector{X: p.X, Y: p.Y},
+		Size:       katnip.Vector{X: w, Y: h},
+		Edge:       katnip.EdgeNone,
+		Layer:      katnip.LayerTop,
+		OutputName: p.Output,
 		// FocusPolicy: katnip.FocusOnDemand,
 		FocusPolicy: katnip.FocusExclusive,
 		ConfigFile:  "NONE",
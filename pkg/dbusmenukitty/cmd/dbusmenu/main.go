@@ -7,19 +7,100 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
+	"os"
 
+	"github.com/nekorg/pawbar/internal/output"
 	"github.com/nekorg/pawbar/pkg/dbusmenukitty"
 )
 
 func main() {
-	var x, y int
+	var (
+		x, y          int
+		service, path string
+		dump, list    bool
+		click         int
+	)
 
-	// flag.StringVar(&service, "service", "", "DBus service name exposing a dbusmenu (e.g. org.freedesktop.network-manager-applet)")
-	flag.IntVar(&x, "x", 0, "X coordinate for panel (pixels)")
-	flag.IntVar(&y, "y", 0, "Y coordinate for panel (pixels)")
+	flag.StringVar(&service, "service", "", "DBus service name exposing a dbusmenu (e.g. org.freedesktop.network-manager-applet)")
+	flag.StringVar(&path, "path", "", "object path of the menu, found by introspecting the service if empty")
+	flag.IntVar(&x, "x", 0, "X coordinate for panel (logical pixels)")
+	flag.IntVar(&y, "y", 0, "Y coordinate for panel (logical pixels)")
+	flag.BoolVar(&dump, "dump", false, "print the menu's layout as JSON instead of opening it")
+	flag.BoolVar(&list, "list", false, "list every dbusmenu on the session bus")
+	flag.IntVar(&click, "click", -1, "send a clicked event to the item with this ID")
 	flag.Parse()
 
+	if err := run(service, path, x, y, dump, list, click); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(service, path string, x, y int, dump, list bool, click int) error {
+	if list {
+		refs, err := dbusmenukitty.ListMenus()
+		if err != nil {
+			return err
+		}
+		for _, r := range refs {
+			fmt.Printf("%s %s\n", r.BusName, r.Path)
+		}
+		return nil
+	}
+
+	if service == "" {
+		return fmt.Errorf("--service is required, see --list")
+	}
+	if path == "" {
+		paths, err := dbusmenukitty.FindMenus(service)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("%s exports no dbusmenu", service)
+		}
+		if len(paths) > 1 {
+			fmt.Fprintf(os.Stderr, "%s exports %d menus, using %s\n", service, len(paths), paths[0])
+		}
+		path = paths[0]
+	}
+
+	switch {
+	case dump:
+		client, err := dbusmenukitty.NewDBusMenuClient(service, path)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		client.AboutToShow(0)
+		layout, err := client.GetLayout()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(layout)
+
+	case click >= 0:
+		client, err := dbusmenukitty.NewDBusMenuClient(service, path)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		return client.SendEvent(int32(click), "clicked", "")
+	}
+
+	// the menu opens on the focused output, submenus need its scale
+	p := dbusmenukitty.Placement{X: x, Y: y}
+	if mon, err := output.Current(); err == nil {
+		p.Output, p.Scale = mon.Name, mon.Scale.X
+	}
+
 	// LaunchMenu will not return until the panel closes (or an error occurs).
-	dbusmenukitty.LaunchMenu(x, y)
+	return dbusmenukitty.LaunchMenu(service, path, p)
 }
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Nekorg All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// SPDX-License-Identifier: bsd
+
+package dbusmenukitty
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	menuInterface     = "com.canonical.dbusmenu"
+	introspectTimeout = time.Second
+	maxDepth          = 8 // ayatana nests menus at /org/ayatana/NotificationItem/<id>/Menu
+)
+
+// MenuRef is a dbusmenu object exported on the session bus.
+type MenuRef struct {
+	BusName string
+	Path    string
+}
+
+// ListMenus walks the object tree of every connection on the session bus and
+// returns each object implementing com.canonical.dbusmenu. BusName is a well
+// known name when the connection owns one, its unique name otherwise.
+func ListMenus() ([]MenuRef, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	var names []string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names); err != nil {
+		return nil, fmt.Errorf("error listing names: %w", err)
+	}
+
+	// walk each connection once, but report it by its nicest name
+	display := make(map[string]string)
+	for _, n := range names {
+		if strings.HasPrefix(n, ":") {
+			if _, ok := display[n]; !ok {
+				display[n] = n
+			}
+			continue
+		}
+		if n == "org.freedesktop.DBus" {
+			continue
+		}
+		var owner string
+		if err := conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, n).Store(&owner); err == nil {
+			display[owner] = n
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		refs []MenuRef
+	)
+	for unique, name := range display {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, p := range findMenus(conn, unique, "/", 0) {
+				mu.Lock()
+				refs = append(refs, MenuRef{BusName: name, Path: p})
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].BusName != refs[j].BusName {
+			return refs[i].BusName < refs[j].BusName
+		}
+		return refs[i].Path < refs[j].Path
+	})
+	return refs, nil
+}
+
+// FindMenus returns the paths of the dbusmenu objects exported by busname.
+func FindMenus(busname string) ([]string, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	paths := findMenus(conn, busname, "/", 0)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func findMenus(conn *dbus.Conn, busname, p string, depth int) []string {
+	if depth > maxDepth {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), introspectTimeout)
+	defer cancel()
+
+	var data string
+	call := conn.Object(busname, dbus.ObjectPath(p)).CallWithContext(ctx, "org.freedesktop.DBus.Introspectable.Introspect", 0)
+	if call.Err != nil || call.Store(&data) != nil {
+		return nil
+	}
+
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(data), &node); err != nil {
+		return nil
+	}
+
+	var out []string
+	for _, iface := range node.Interfaces {
+		if iface.Name == menuInterface {
+			out = append(out, p)
+			break
+		}
+	}
+	for _, child := range node.Children {
+		out = append(out, findMenus(conn, busname, path.Join(p, child.Name), depth+1)...)
+	}
+	return out
+}
@@ -6,22 +6,110 @@
 
 package menu
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ToggleWidth  = 2 // mark + space
+	ShortcutGap  = 3 // min space between label and shortcut
+	iconWidth    = 2
+	checkOn      = "☑"
+	checkOff     = "☐"
+	checkUnknown = "▣"
+	radioOn      = "◉"
+	radioOff     = "○"
+	radioUnknown = "◌"
+)
+
+// MaxLengthLabel is the width of the widest item without the menu padding,
+// counting the toggle column and the shortcut column if any item has them
 func MaxLengthLabel(labels []Item) int {
 	if len(labels) == 0 {
 		return 0
 	}
-  
-  maxLen := 0
+
+	maxLen, maxShortcut := 0, 0
 	for _, l := range labels {
 		curLen := len(l.Label.Display)
 
 		if l.IconData != nil || l.IconName != "" {
-			curLen += 2
+			curLen += iconWidth
 		}
 		if curLen > maxLen {
 			maxLen = curLen
 		}
+		maxShortcut = max(maxShortcut, utf8.RuneCountInString(ShortcutLabel(l.Shortcut)))
+	}
+
+	if HasToggles(labels) {
+		maxLen += ToggleWidth
+	}
+	if maxShortcut > 0 {
+		maxLen += ShortcutGap + maxShortcut
 	}
 
 	return maxLen
 }
+
+// HasToggles reports whether any item is a checkbox or radio item, the
+// toggle column is reserved for all items then so labels stay aligned
+func HasToggles(items []Item) bool {
+	for _, it := range items {
+		if it.ToggleType == ToggleCheckMark || it.ToggleType == ToggleRadio {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleMark is the check or radio mark of item, empty if it has none
+func ToggleMark(item Item) string {
+	switch item.ToggleType {
+	case ToggleCheckMark:
+		switch int(item.ToggleState) {
+		case StateOn:
+			return checkOn
+		case StateOff:
+			return checkOff
+		default:
+			return checkUnknown
+		}
+	case ToggleRadio:
+		switch int(item.ToggleState) {
+		case StateOn:
+			return radioOn
+		case StateOff:
+			return radioOff
+		default:
+			return radioUnknown
+		}
+	}
+	return ""
+}
+
+var keyNames = map[string]string{
+	"Control": "Ctrl",
+}
+
+// ShortcutLabel formats a dbusmenu shortcut ([["Control", "q"]]) as
+// "Ctrl+Q", chords are separated by a comma
+func ShortcutLabel(shortcut [][]string) string {
+	chords := make([]string, 0, len(shortcut))
+	for _, chord := range shortcut {
+		keys := make([]string, 0, len(chord))
+		for _, k := range chord {
+			if n, ok := keyNames[k]; ok {
+				k = n
+			} else if utf8.RuneCountInString(k) == 1 {
+				k = strings.ToUpper(k)
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) > 0 {
+			chords = append(chords, strings.Join(keys, "+"))
+		}
+	}
+	return strings.Join(chords, ", ")
+}
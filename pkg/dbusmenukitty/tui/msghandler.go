@@ -105,6 +105,7 @@ func (h *MessageHandler) handleMouseMotion(col, row int) {
 
 
 	h.state.mouseX = col
+	h.state.keyboard = false
 	// If mouse hasn't actually moved to a different row, ignore
 	if h.state.mouseY == row && h.state.mouseOnSurface {
 		return
@@ -136,8 +137,69 @@ func (h *MessageHandler) handleKeyNavigation(keyPressed bool) {
 	if !keyPressed || !h.state.isValidItemIndex(h.state.mouseY) {
 		return
 	}
+	h.state.keyboard = true
 
 	h.state.cancelHoverTimer()
 	currentItem := &h.state.items[h.state.mouseY]
 	h.handleItemHover(currentItem)
 }
+
+// handleItemActivate is the keyboard version of a click: it works wherever
+// the mouse is, and opens submenus instead of clicking them
+func (h *MessageHandler) handleItemActivate(index int) {
+	if !h.state.isValidItemIndex(index) {
+		return
+	}
+	item := &h.state.items[index]
+	if item.Type == menu.ItemSeparator || !item.Enabled {
+		return
+	}
+
+	h.state.mouseY = index
+	h.state.keyboard = true
+	if item.HasChildren {
+		h.handleSubmenuOpen(index)
+		return
+	}
+	h.sendMessage(menu.MsgItemClicked, item.Id, 0, 0)
+}
+
+func (h *MessageHandler) handleSubmenuOpen(index int) {
+	item := &h.state.items[index]
+	if !item.HasChildren || !item.Enabled {
+		return
+	}
+
+	h.state.cancelHoverTimer()
+	if h.state.hoverItemId != 0 && h.state.hoverItemId != item.Id {
+		h.handleSubmenuCancel()
+	}
+	h.state.hoverItemId = item.Id
+	h.sendMessage(menu.MsgSubmenuRequested, item.Id, 0, index)
+}
+
+// handleMnemonic activates the item with access key r, when several items
+// share it the selection cycles through them instead. returns false if no
+// item has r as its access key
+func (h *MessageHandler) handleMnemonic(r rune) bool {
+	matches := h.state.mnemonic(r)
+	switch len(matches) {
+	case 0:
+		return false
+	case 1:
+		h.handleItemActivate(matches[0])
+		return true
+	}
+
+	next := matches[0]
+	for _, i := range matches {
+		if i > h.state.mouseY {
+			next = i
+			break
+		}
+	}
+	h.state.cancelHoverTimer()
+	h.state.mouseY = next
+	h.handleKeyNavigation(true)
+	return true
+}
@@ -45,7 +45,7 @@ func (r *Renderer) drawBackground(row int, style vaxis.Style) {
 func (r *Renderer) getItemStyle(item *menu.Item, row int, state *MenuState) vaxis.Style {
 	var style vaxis.Style
 
-	if row == state.mouseY && state.mouseOnSurface {
+	if state.isHighlighted(row) {
 		if state.mousePressed {
 			style.Background = vaxis.ColorBlue
 		} else {
@@ -61,7 +61,7 @@ func (r *Renderer) getItemStyle(item *menu.Item, row int, state *MenuState) vaxi
 	return style
 }
 
-func (r *Renderer) renderIcon(item *menu.Item, row int, defaultColor color.Color) (prefixAdd string) {
+func (r *Renderer) renderIcon(item *menu.Item, row, col int, defaultColor color.Color) (prefixAdd string) {
 	var img image.Image
 	var err error
 
@@ -84,12 +84,12 @@ func (r *Renderer) renderIcon(item *menu.Item, row int, defaultColor color.Color
 	kimg.Resize(iconCellWidth, iconCellHeight)
 	iw, ih := kimg.CellSize()
 	log.Trace("kitty image size: %d, %d", iw, ih)
-	kimg.Draw(r.win.New(menuPadding, row, iw, ih))
+	kimg.Draw(r.win.New(col, row, iw, ih))
 
 	return strings.Repeat(" ", iconSpacing)
 }
 
-func (r *Renderer) drawItem(item *menu.Item, row int, state *MenuState, showIcons bool) {
+func (r *Renderer) drawItem(item *menu.Item, row int, state *MenuState, showIcons, toggles bool) {
 	style := r.getItemStyle(item, row, state)
 	defaultColor := fgColor
 
@@ -106,18 +106,40 @@ func (r *Renderer) drawItem(item *menu.Item, row int, state *MenuState, showIcon
 		prefix = string(arrowHeads[0]) + prefix[1:]
 	}
 
+	// toggle column is kept for every item once one item has it
+	if toggles {
+		mark := menu.ToggleMark(*item)
+		if mark == "" {
+			mark = " "
+		}
+		prefix += mark + strings.Repeat(" ", menu.ToggleWidth-1)
+	}
+
 	// Add icon if present and rendering full menu
 	if showIcons {
-		prefix += r.renderIcon(item, row, defaultColor)
+		prefix += r.renderIcon(item, row, r.win.Vx.RenderedWidth(prefix), defaultColor)
 	} else if item.IconData != nil || item.IconName != "" {
 		// Reserve space for icon in fast draw
 		prefix += strings.Repeat(" ", iconSpacing)
 	}
 
 	// Draw text
-	suffix := strings.Repeat(" ", menuPadding)
-	text := prefix + item.Label.Display + suffix
-	r.win.Println(row, vaxis.Segment{Text: text, Style: style})
+	segs := append([]vaxis.Segment{{Text: prefix, Style: style}}, labelSegments(item.Label, style, state.query)...)
+	r.win.Println(row, segs...)
+	textWidth := r.win.Vx.RenderedWidth(prefix + item.Label.Display)
+
+	// shortcut is right aligned, dimmed unless the item is hovered
+	if shortcut := menu.ShortcutLabel(item.Shortcut); shortcut != "" {
+		w, _ := r.win.Size()
+		col := w - menuPadding - r.win.Vx.RenderedWidth(shortcut)
+		if col > textWidth {
+			scStyle := style
+			if !state.isHighlighted(row) {
+				scStyle.Attribute |= vaxis.AttrDim
+			}
+			r.win.New(col, row, w-col, 1).Println(0, vaxis.Segment{Text: shortcut, Style: scStyle})
+		}
+	}
 }
 
 func (r *Renderer) drawSeparator(row int) {
@@ -129,13 +151,58 @@ func (r *Renderer) drawSeparator(row int) {
 }
 
 func (r *Renderer) drawMenu(items []menu.Item, state *MenuState, showIcons bool) {
+	if len(items) == 0 && state.query != "" {
+		r.win.Println(0, vaxis.Segment{
+			Text:  strings.Repeat(" ", menuPadding) + "no match for " + state.query,
+			Style: vaxis.Style{Attribute: vaxis.AttrDim | vaxis.AttrItalic},
+		})
+		return
+	}
+
+	toggles := menu.HasToggles(items)
 	for i, item := range items {
 		if item.Type == menu.ItemSeparator {
 			r.drawSeparator(i)
 		} else {
-			r.drawItem(&item, i, state, showIcons)
+			r.drawItem(&item, i, state, showIcons, toggles)
+		}
+	}
+}
+
+// labelSegments splits the label so the access key is underlined and the
+// part matching the type-ahead query is bold
+func labelSegments(label menu.Label, style vaxis.Style, query string) []vaxis.Segment {
+	runes := []rune(label.Display)
+	mStart, mEnd := -1, -1
+	if query != "" {
+		lower := []rune(strings.ToLower(label.Display))
+		q := []rune(strings.ToLower(query))
+		// lowercasing can change the length of a few runes, only highlight
+		// when the indices still line up
+		if len(lower) == len(runes) {
+			if i := strings.Index(string(lower), string(q)); i >= 0 {
+				mStart = len([]rune(string(lower)[:i]))
+				mEnd = mStart + len(q)
+			}
+		}
+	}
+
+	var segs []vaxis.Segment
+	for i, ch := range runes {
+		st := style
+		if label.Found && i == label.AccessIndex {
+			st.UnderlineStyle = vaxis.UnderlineSingle
+		}
+		if i >= mStart && i < mEnd {
+			st.Attribute |= vaxis.AttrBold
+		}
+		if n := len(segs); n > 0 && segs[n-1].Style == st {
+			segs[n-1].Text += string(ch)
+			continue
 		}
+		segs = append(segs, vaxis.Segment{Text: string(ch), Style: st})
 	}
+	return segs
 }
 
 func renderIcon(icon xdgicons.Icon, c color.Color) (image.Image, error) {
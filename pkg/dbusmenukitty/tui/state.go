@@ -7,13 +7,17 @@
 package tui
 
 import (
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/nekorg/pawbar/pkg/dbusmenukitty/menu"
 )
 
 type MenuState struct {
-	items          []menu.Item
+	items          []menu.Item // what is shown, all filtered by query
+	all            []menu.Item
+	query          string
 	mouseX         int
 	mouseY         int
 	mousePixelX    int
@@ -21,6 +25,7 @@ type MenuState struct {
 	lastMouseY     int
 	mousePressed   bool
 	mouseOnSurface bool
+	keyboard       bool // selection was last moved with keys
 	hoverTimer     *time.Timer
 	hoverItemId    int32
 	ppc            menu.PPC
@@ -57,6 +62,11 @@ func (m *MenuState) isSelectableItem(index int) bool {
 		!m.mouseOnSurface
 }
 
+// isHighlighted reports whether row is the selected row
+func (m *MenuState) isHighlighted(row int) bool {
+	return row == m.mouseY && (m.mouseOnSurface || m.keyboard)
+}
+
 func (m *MenuState) getCurrentItem() *menu.Item {
 	if !m.isValidItemIndex(m.mouseY) {
 		return nil
@@ -89,3 +99,54 @@ func (m *MenuState) navigateDown() {
 		}
 	}
 }
+
+// setItems replaces the menu, keeping the current filter
+func (m *MenuState) setItems(items []menu.Item) {
+	m.all = items
+	m.applyFilter()
+}
+
+func (m *MenuState) setQuery(q string) {
+	m.query = q
+	m.applyFilter()
+	if q != "" {
+		// select the first match
+		m.mouseY = -1
+		m.navigateDown()
+	}
+}
+
+// applyFilter keeps the items whose label contains the query, separators are
+// dropped while filtering
+func (m *MenuState) applyFilter() {
+	if m.query == "" {
+		m.items = m.all
+	} else {
+		q := strings.ToLower(m.query)
+		m.items = nil
+		for _, it := range m.all {
+			if it.Type != menu.ItemSeparator && strings.Contains(strings.ToLower(it.Label.Display), q) {
+				m.items = append(m.items, it)
+			}
+		}
+	}
+
+	// the hovered row may be gone or be something else now
+	if !m.isValidItemIndex(m.mouseY) {
+		m.cancelHoverTimer()
+		m.mouseY = -1
+		m.lastMouseY = -1
+	}
+}
+
+// mnemonic returns the enabled items whose access key is r
+func (m *MenuState) mnemonic(r rune) []int {
+	var out []int
+	r = unicode.ToLower(r)
+	for i, it := range m.items {
+		if it.Type != menu.ItemSeparator && it.Enabled && it.Label.Found && unicode.ToLower(it.Label.AccessKey) == r {
+			out = append(out, i)
+		}
+	}
+	return out
+}
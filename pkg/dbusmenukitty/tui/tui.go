@@ -151,14 +151,33 @@ func Leaf(k *katnip.Kitty, rw io.ReadWriter) int {
 			case vaxis.Key:
 				if ev.EventType == vaxis.EventPress {
 					switch ev.Keycode {
-					case vaxis.KeyEsc, vaxis.KeyLeft:
+					case vaxis.KeyEsc:
+						// clear the filter first
+						if state.query != "" {
+							messageHandler.handleSubmenuCancel()
+							state.setQuery("")
+							win.Clear()
+							renderer.drawMenu(state.items, state, true)
+							vx.Render()
+							break
+						}
 						return 0
 
-					case vaxis.KeyEnter:
-						if state.isSelectableItem(state.mouseY) {
-							messageHandler.handleItemClick(&state.items[state.mouseY])
+					case vaxis.KeyLeft:
+						return 0
+
+					case vaxis.KeyBackspace:
+						if q := []rune(state.query); len(q) > 0 {
+							messageHandler.handleSubmenuCancel()
+							state.setQuery(string(q[:len(q)-1]))
+							win.Clear()
+							renderer.drawMenu(state.items, state, true)
+							vx.Render()
 						}
 
+					case vaxis.KeyEnter:
+						messageHandler.handleItemActivate(state.mouseY)
+
 					case vaxis.KeyUp:
 						state.navigateUp()
 						messageHandler.handleKeyNavigation(true)
@@ -172,10 +191,31 @@ func Leaf(k *katnip.Kitty, rw io.ReadWriter) int {
 						vx.Render()
 
 					case vaxis.KeyRight:
-						if item := state.getCurrentItem(); item != nil && item.HasChildren && item.Enabled {
-							messageHandler.sendMessage(menu.MsgSubmenuRequested, item.Id,
-								state.mousePixelX, state.mousePixelY)
+						if state.isValidItemIndex(state.mouseY) {
+							messageHandler.handleSubmenuOpen(state.mouseY)
+						}
+
+					default:
+						if ev.Text == "" || ev.Modifiers&^(vaxis.ModShift|vaxis.ModAlt|vaxis.ModCapsLock|vaxis.ModNumLock) != 0 {
+							break
 						}
+						// mnemonics win until a filter is being typed, alt
+						// forces them
+						r := []rune(ev.Text)[0]
+						if (state.query == "" || ev.Modifiers&vaxis.ModAlt != 0) && messageHandler.handleMnemonic(r) {
+							renderer.drawMenu(state.items, state, false)
+							vx.Render()
+							break
+						}
+						if ev.Modifiers&vaxis.ModAlt != 0 {
+							break
+						}
+						messageHandler.handleSubmenuCancel()
+						state.setQuery(state.query + ev.Text)
+						state.keyboard = true
+						win.Clear()
+						renderer.drawMenu(state.items, state, true)
+						vx.Render()
 					}
 				}
 			}
@@ -186,9 +226,10 @@ func Leaf(k *katnip.Kitty, rw io.ReadWriter) int {
 				return 0
 
 			case menu.MsgMenuUpdate:
-				state.items = msg.Payload.Menu
-				maxHorizontalLength := menu.MaxLengthLabel(state.items) + 4
-				maxVerticalLength := len(state.items)
+				state.setItems(msg.Payload.Menu)
+				// size for the whole menu, not just what the filter shows
+				maxHorizontalLength := menu.MaxLengthLabel(state.all) + 4
+				maxVerticalLength := len(state.all)
 
 				log.Info("leaf: %d %d actual: %d %d\n", maxHorizontalLength, maxVerticalLength, state.size.Cols, state.size.Rows)
 